@@ -0,0 +1,106 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+)
+
+// ValuesAll is like Values for many strings: it returns the values for each
+// input, or nil for the inputs that don't match. The values of all the
+// inputs share a single backing array, so it allocates less than calling
+// Values for each one.
+func (r *Regexp) ValuesAll(inputs []string) []url.Values {
+	all := make([]url.Values, len(inputs))
+	buf := make([]string, 0, len(inputs)*(len(r.groups)+len(r.defaults)))
+	for i, s := range inputs {
+		var values url.Values
+		if !r.Visit(s, func(name, value string) bool {
+			if values == nil {
+				values = make(url.Values, len(r.groups))
+			}
+			if v, ok := values[name]; ok {
+				values[name] = append(v, value)
+				return true
+			}
+			if len(buf) == cap(buf) {
+				buf = make([]string, 0, cap(buf))
+			}
+			buf = append(buf, value)
+			values[name] = buf[len(buf)-1 : len(buf) : len(buf)]
+			return true
+		}) {
+			continue
+		}
+		if values == nil {
+			values = url.Values{}
+		}
+		all[i] = values
+	}
+	return all
+}
+
+// Iter returns an iterator over the inputs that match the regexp. It reuses
+// the same values for each input, so it doesn't allocate once their slices
+// have grown, which is useful to scan many strings, like log lines:
+//
+//	it := re.Iter(paths)
+//	for it.Next() {
+//		counts[it.Values().Get("id")]++
+//	}
+func (r *Regexp) Iter(inputs []string) *ValuesIterator {
+	return &ValuesIterator{re: r, inputs: inputs, index: -1,
+		values: url.Values{}}
+}
+
+// ValuesIterator iterates over the inputs matching a regexp; see
+// Regexp.Iter.
+type ValuesIterator struct {
+	re     *Regexp
+	inputs []string
+	index  int
+	values url.Values
+}
+
+// Next advances to the next matching input, and returns false when there
+// are none left.
+func (it *ValuesIterator) Next() bool {
+	for it.index++; it.index < len(it.inputs); it.index++ {
+		for name, v := range it.values {
+			it.values[name] = v[:0]
+		}
+		if it.re.Visit(it.inputs[it.index], it.add) {
+			for name, v := range it.values {
+				if len(v) == 0 {
+					delete(it.values, name)
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// add adds a value of the current input.
+func (it *ValuesIterator) add(name, value string) bool {
+	it.values[name] = append(it.values[name], value)
+	return true
+}
+
+// Index returns the index of the current input.
+func (it *ValuesIterator) Index() int {
+	return it.index
+}
+
+// Input returns the current input.
+func (it *ValuesIterator) Input() string {
+	return it.inputs[it.index]
+}
+
+// Values returns the values of the current input. They are only valid until
+// the next call to Next.
+func (it *ValuesIterator) Values() url.Values {
+	return it.values
+}
@@ -0,0 +1,95 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+var batchInputs = []string{"/users/1", "/about", "/en/users/2", "/users/3/x"}
+
+func TestValuesAll(t *testing.T) {
+	re := MustCompileRegexp(`^(?:/(?P<lang>en|fr))?/users/(?P<id>\d+)$`)
+	expected := []url.Values{
+		{"id": {"1"}},
+		nil,
+		{"lang": {"en"}, "id": {"2"}},
+		nil,
+	}
+	all := re.ValuesAll(batchInputs)
+	if len(all) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(all))
+	}
+	for i, values := range all {
+		if (values == nil) != (expected[i] == nil) || !equalValues(expected[i], values) {
+			t.Errorf("%s: expected %v, got %v", batchInputs[i], expected[i], values)
+		}
+	}
+	// Appending to a value doesn't change the others.
+	all[0]["id"] = append(all[0]["id"], "9")
+	if all[2].Get("lang") != "en" {
+		t.Errorf("Expected lang=en, got %v", all[2])
+	}
+}
+
+func TestValuesIterator(t *testing.T) {
+	re := MustCompileRegexp(`^(?:/(?P<lang>en|fr))?/users/(?P<id>\d+)$`)
+	var got []string
+	it := re.Iter(batchInputs)
+	for it.Next() {
+		got = append(got, fmt.Sprintf("%d %s %v", it.Index(), it.Input(), it.Values()))
+	}
+	expected := []string{
+		"0 /users/1 map[id:[1]]",
+		"2 /en/users/2 map[id:[2] lang:[en]]",
+	}
+	if !stringSliceEqual(expected, got) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if it.Next() {
+		t.Errorf("Expected no more inputs")
+	}
+}
+
+func benchmarkInputs() []string {
+	inputs := make([]string, 1000)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("/users/%d/posts/%d", i, i%7)
+	}
+	return inputs
+}
+
+func BenchmarkValuesEach(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	inputs := benchmarkInputs()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, s := range inputs {
+			re.Values(s)
+		}
+	}
+}
+
+func BenchmarkValuesAll(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	inputs := benchmarkInputs()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		re.ValuesAll(inputs)
+	}
+}
+
+func BenchmarkValuesIterator(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	inputs := benchmarkInputs()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		it := re.Iter(inputs)
+		for it.Next() {
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// BuildFromStruct builds a URL using variables read from the fields of a
+// struct, or a pointer to a struct, with a "reverse" tag:
+//
+//	type userURL struct {
+//		ID   int    `reverse:"id"`
+//		Tab  string `reverse:"tab"`
+//	}
+//
+//	u, err := reverse.BuildFromStruct(path, userURL{ID: 42, Tab: "info"})
+//
+// Fields can be strings, booleans, numbers, types implementing
+// encoding.TextMarshaler, or slices and pointers of those. Nil pointers are
+// skipped.
+func BuildFromStruct(b Builder, v interface{}) (*url.URL, error) {
+	values, err := StructValues(v)
+	if err != nil {
+		return nil, err
+	}
+	u := &url.URL{}
+	if err := b.Build(u, values); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// StructValues returns the values of the tagged fields of a struct, as used
+// by BuildFromStruct.
+func StructValues(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Expected a struct, got %T", v)
+	}
+	values := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, ok := fieldName(rt.Field(i))
+		if !ok {
+			continue
+		}
+		if err := appendField(values, name, rv.Field(i)); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Decode sets the fields of the struct pointed to by dst with a "reverse"
+// tag to the extracted values, using the same tags as BuildFromStruct:
+//
+//	var params struct {
+//		ID int `reverse:"id"`
+//	}
+//	err := result.Decode(&params)
+//
+// Values are converted to the field types; types implementing
+// encoding.TextUnmarshaler, like time.Time, are supported. Fields without
+// values are left unchanged.
+func (r *Result) Decode(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Expected a pointer to a struct, got %T", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, ok := fieldName(rt.Field(i))
+		if !ok || len(r.Values[name]) == 0 {
+			continue
+		}
+		if err := setField(rv.Field(i), name, r.Values[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldName returns the variable name set in a struct field tag.
+func fieldName(f reflect.StructField) (string, bool) {
+	name := f.Tag.Get("reverse")
+	if name == "" || name == "-" || f.PkgPath != "" {
+		return "", false
+	}
+	return name, true
+}
+
+// appendField adds the string form of a field value to the values.
+func appendField(values url.Values, name string, v reflect.Value) error {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		text, err := m.MarshalText()
+		if err != nil {
+			return fmt.Errorf("Invalid value for variable %q: %v", name, err)
+		}
+		values.Add(name, string(text))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return appendField(values, name, v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := appendField(values, name, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		values.Add(name, v.String())
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		values.Add(name, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		values.Add(name, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(name, strconv.FormatFloat(v.Float(), 'f', -1,
+			v.Type().Bits()))
+	default:
+		return fmt.Errorf("Unsupported type %s for variable %q", v.Type(),
+			name)
+	}
+	return nil
+}
+
+// setField sets a field value from its string form. Slices get all values,
+// other types the first one.
+func setField(v reflect.Value, name string, values []string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setField(v.Elem(), name, values)
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(values[0])); err != nil {
+			return fmt.Errorf("Invalid value %q for variable %q: %v",
+				values[0], name, err)
+		}
+		return nil
+	}
+	var err error
+	s := values[0]
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for k, value := range values {
+			err := setField(slice.Index(k), name, []string{value})
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, v.Type().Bits())
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		var i uint64
+		i, err = strconv.ParseUint(s, 10, v.Type().Bits())
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, v.Type().Bits())
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("Unsupported type %s for variable %q", v.Type(),
+			name)
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid value %q for variable %q: %v", s, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBuildFromStruct(t *testing.T) {
+	type params struct {
+		ID     int       `reverse:"id"`
+		Tab    *string   `reverse:"tab"`
+		Draft  bool      `reverse:"draft"`
+		Since  time.Time `reverse:"since"`
+		Tags   []string  `reverse:"tag"`
+		Ignore string    `reverse:"-"`
+		Other  string
+	}
+	tab := "info"
+	v := params{
+		ID:    42,
+		Tab:   &tab,
+		Draft: true,
+		Since: time.Date(2012, 1, 2, 0, 0, 0, 0, time.UTC),
+		Tags:  []string{"a", "b"},
+	}
+	values, err := StructValues(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := url.Values{
+		"id":    {"42"},
+		"tab":   {"info"},
+		"draft": {"true"},
+		"since": {"2012-01-02T00:00:00Z"},
+		"tag":   {"a", "b"},
+	}
+	if !equalValues(expect, values) {
+		t.Errorf("expected %v, got %v", expect, values)
+	}
+
+	path := MustNewGorillaPath("/users/{id:[0-9]+}", false)
+	u, err := BuildFromStruct(path, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/users/42" {
+		t.Errorf("expected %q, got %q", "/users/42", u.Path)
+	}
+	if _, err := BuildFromStruct(path, 42); err == nil {
+		t.Errorf("expected error for non-struct value")
+	}
+	if _, err := StructValues(struct {
+		C chan int `reverse:"c"`
+	}{}); err == nil {
+		t.Errorf("expected error for unsupported type")
+	}
+}
+
+func TestResultDecode(t *testing.T) {
+	var params struct {
+		ID    int       `reverse:"id"`
+		Page  *uint     `reverse:"page"`
+		Draft bool      `reverse:"draft"`
+		Since time.Time `reverse:"since"`
+		Tags  []string  `reverse:"tag"`
+		Score float64   `reverse:"score"`
+		Other string    `reverse:"other"`
+	}
+	params.Other = "unchanged"
+	result := &Result{Values: url.Values{
+		"id":    {"42"},
+		"page":  {"3"},
+		"draft": {"true"},
+		"since": {"2012-01-02T00:00:00Z"},
+		"tag":   {"a", "b"},
+		"score": {"1.5"},
+	}}
+	if err := result.Decode(&params); err != nil {
+		t.Fatal(err)
+	}
+	if params.ID != 42 || params.Page == nil || *params.Page != 3 ||
+		!params.Draft || params.Score != 1.5 || params.Other != "unchanged" ||
+		!params.Since.Equal(time.Date(2012, 1, 2, 0, 0, 0, 0, time.UTC)) ||
+		len(params.Tags) != 2 || params.Tags[1] != "b" {
+		t.Errorf("unexpected decoded struct %+v", params)
+	}
+
+	result = &Result{Values: url.Values{"id": {"a"}}}
+	if err := result.Decode(&params); err == nil {
+		t.Errorf("expected error for invalid integer")
+	}
+	if err := result.Decode(params); err == nil {
+		t.Errorf("expected error for non-pointer value")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultRegexpCache is the cache used by CompileRegexpCached.
+var DefaultRegexpCache = NewRegexpCache(256)
+
+// CompileRegexpCached is like CompileRegexp, but returns a copy of the regexp
+// from DefaultRegexpCache if the pattern was already compiled.
+func CompileRegexpCached(pattern string) (*Regexp, error) {
+	return DefaultRegexpCache.Compile(pattern)
+}
+
+// NewRegexpCache returns a cache keeping up to size compiled regexps; the
+// least recently used ones are dropped first. A size of zero or less means
+// no limit.
+func NewRegexpCache(size int) *RegexpCache {
+	return &RegexpCache{
+		size:  size,
+		order: list.New(),
+		items: map[regexpCacheKey]*list.Element{},
+	}
+}
+
+// RegexpCache is a least recently used cache of compiled regexps, keyed by
+// pattern and options. It is safe for concurrent use.
+type RegexpCache struct {
+	mu     sync.Mutex
+	size   int
+	order  *list.List // of *regexpCacheEntry, most recently used first
+	items  map[regexpCacheKey]*list.Element
+	hits   uint64
+	misses uint64
+}
+
+// CacheStats are the statistics of a RegexpCache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Len    int // number of cached regexps
+}
+
+// HitRate returns the ratio of hits to lookups, or zero if there were no
+// lookups.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// regexpCacheKey identifies a compiled regexp.
+type regexpCacheKey struct {
+	pattern string
+	opts    options
+}
+
+// regexpCacheEntry is an element of the cache list.
+type regexpCacheEntry struct {
+	key    regexpCacheKey
+	regexp *Regexp
+}
+
+// Compile is like CompileRegexpWith, but returns a copy of the cached regexp
+// if the pattern was already compiled with the same options. Errors are not
+// cached.
+func (c *RegexpCache) Compile(pattern string, opts ...Option) (*Regexp, error) {
+	return c.compile(pattern, newOptions(opts))
+}
+
+// Stats returns the cache statistics.
+func (c *RegexpCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Len: c.order.Len()}
+}
+
+// Clear removes all cached regexps and resets the statistics.
+func (c *RegexpCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = map[regexpCacheKey]*list.Element{}
+	c.hits, c.misses = 0, 0
+}
+
+// compile returns a copy of the cached regexp, compiling it on a miss. The
+// regexp is compiled without holding the lock, so concurrent misses for the
+// same pattern may compile it more than once.
+func (c *RegexpCache) compile(pattern string, o options) (*Regexp, error) {
+	// Only the options used to compile are part of the key.
+	o = options{caseFold: o.caseFold, groupLiterals: o.groupLiterals,
+		duplicates: o.duplicates, escaping: o.escaping,
+		strictTemplate: o.strictTemplate, flags: o.flags,
+		customFlags: o.customFlags}
+	key := regexpCacheKey{pattern: pattern, opts: o}
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.hits++
+		c.order.MoveToFront(e)
+		r := *e.Value.(*regexpCacheEntry).regexp
+		c.mu.Unlock()
+		return &r, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+	compiled, err := compileRegexp(pattern, o)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if _, ok := c.items[key]; !ok {
+		c.items[key] = c.order.PushFront(&regexpCacheEntry{key, compiled})
+		if c.size > 0 && c.order.Len() > c.size {
+			last := c.order.Back()
+			c.order.Remove(last)
+			delete(c.items, last.Value.(*regexpCacheEntry).key)
+		}
+	}
+	c.mu.Unlock()
+	r := *compiled
+	return &r, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestRegexpCache(t *testing.T) {
+	c := NewRegexpCache(2)
+	a, err := c.Compile(`^/users/(?P<id>\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Compile(`^/users/(?P<id>\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || a.Compiled() != b.Compiled() {
+		t.Errorf("expected a copy of the same compiled regexp")
+	}
+	if s, err := b.Revert(url.Values{"id": {"42"}}); err != nil || s != "/users/42" {
+		t.Errorf("expected %q, got %q, %v", "/users/42", s, err)
+	}
+	if _, err := c.Compile(`^/users/(?P<id>\d+)$`, WithCaseFold()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile(`^/items/(\d+)$`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Compile(`^/users/(?P<id>\d+`); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Len != 2 || stats.HitRate() != 0.2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	// The first pattern was evicted.
+	if _, err := c.Compile(`^/users/(?P<id>\d+)$`); err != nil {
+		t.Fatal(err)
+	}
+	if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 5 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	c.Clear()
+	if stats := c.Stats(); stats != (CacheStats{}) || stats.HitRate() != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestWithRegexpCache(t *testing.T) {
+	c := NewRegexpCache(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := NewGorillaPath("/users/{id=me}", false, WithRegexpCache(c)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := c.Stats(); stats.Hits+stats.Misses != 40 || stats.Len != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	a := MustNewGorillaPath("/users/{id:[0-9]+}", false, WithRegexpCache(c))
+	b := MustNewGorillaPath("/users/{id:[0-9]+}", false)
+	if !Equal(a, b) {
+		t.Errorf("expected cached matcher to be equal to uncached one")
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", a, r, true)
+}
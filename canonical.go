@@ -0,0 +1,62 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NewCanonicalHost returns a matcher for requests not using the given host
+// or scheme, which redirects with 301 (http.StatusMovedPermanently). An
+// empty scheme keeps the request one.
+func NewCanonicalHost(host, scheme string) CanonicalHost {
+	return CanonicalHost{Host: host, Scheme: scheme,
+		Code: http.StatusMovedPermanently}
+}
+
+// CanonicalHost matches requests whose host or scheme differ from the
+// canonical ones. Hosts are compared ignoring case, and the request port is
+// only compared if Host has one. Extract sets a redirect to the same URL
+// with the canonical host and scheme; a zero Code uses 301. Use 308
+// (http.StatusPermanentRedirect) to keep the request method.
+//
+// Register it before other routes, for example to redirect
+// "http://www.example.com" to "https://example.com":
+//
+//	router.Handle("", nil, reverse.NewCanonicalHost("example.com", "https"))
+type CanonicalHost struct {
+	Host   string
+	Scheme string
+	Code   int
+}
+
+func (m CanonicalHost) Match(r *http.Request) bool {
+	host := getHost(r)
+	if strings.Contains(m.Host, ":") {
+		host = getHostPort(r)
+	}
+	return !strings.EqualFold(host, m.Host) ||
+		m.Scheme != "" && requestScheme(r) != m.Scheme
+}
+
+func (m CanonicalHost) String() string {
+	return fmt.Sprintf("CanonicalHost(%q, %q, %d)", m.Host, m.Scheme, m.Code)
+}
+
+// Extract sets a redirect handler to the canonical URL.
+func (m CanonicalHost) Extract(result *Result, r *http.Request) {
+	if result.Handler != nil || !m.Match(r) {
+		return
+	}
+	code := redirectCode(m.Code)
+	u := *r.URL
+	u.Scheme, u.Host = m.Scheme, m.Host
+	if u.Scheme == "" {
+		u.Scheme = requestScheme(r)
+	}
+	result.Handler = http.RedirectHandler(u.String(), code)
+}
@@ -0,0 +1,65 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+)
+
+// NewCleanPath returns a matcher for URL paths that need cleaning, which
+// redirects with 301 (http.StatusMovedPermanently).
+func NewCleanPath() CleanPath {
+	return CleanPath{Code: http.StatusMovedPermanently}
+}
+
+// CleanPath matches URL paths that are not in canonical form: with double
+// slashes, or "." or ".." segments. Extract sets a redirect to the cleaned
+// path, keeping the trailing slash; a zero Code uses 301.
+//
+// Register it before other routes so that they never see paths like
+// "/static/../admin":
+//
+//	router.Handle("", nil, reverse.NewCleanPath())
+type CleanPath struct {
+	Code int
+}
+
+func (m CleanPath) Match(r *http.Request) bool {
+	return cleanPath(r.URL.Path) != r.URL.Path
+}
+
+func (m CleanPath) String() string {
+	return fmt.Sprintf("CleanPath(%d)", m.Code)
+}
+
+// Extract sets a redirect handler to the cleaned path.
+func (m CleanPath) Extract(result *Result, r *http.Request) {
+	p := cleanPath(r.URL.Path)
+	if result.Handler != nil || p == r.URL.Path {
+		return
+	}
+	code := redirectCode(m.Code)
+	u := *r.URL
+	u.Path, u.RawPath = p, ""
+	result.Handler = http.RedirectHandler(u.String(), code)
+}
+
+// cleanPath returns the canonical form of a path, keeping the trailing
+// slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
@@ -0,0 +1,53 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	const name = "CleanPath"
+	tests := []struct {
+		rURL     string
+		expect   bool
+		location string
+	}{
+		{"http://domain.com/a/b", false, ""},
+		{"http://domain.com/a/b/", false, ""},
+		{"http://domain.com/", false, ""},
+		{"http://domain.com//a//b", true, "http://domain.com/a/b"},
+		{"http://domain.com/a/./b/", true, "http://domain.com/a/b/"},
+		{"http://domain.com/static/../admin?x=1", true, "http://domain.com/admin?x=1"},
+		{"http://domain.com/..", true, "http://domain.com/"},
+	}
+	matcher := NewCleanPath()
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !v.expect {
+			if result.Handler != nil {
+				t.Errorf("%s: unexpected redirect for %s", name, v.rURL)
+			}
+			continue
+		}
+		if result.Handler == nil {
+			t.Errorf("%s: expected redirect for %s", name, v.rURL)
+			continue
+		}
+		w := httptest.NewRecorder()
+		result.Handler.ServeHTTP(w, r)
+		if loc := w.Header().Get("Location"); w.Code != http.StatusMovedPermanently || loc != v.location {
+			t.Errorf("%s: expected redirect to %q, got %d %q", name, v.location, w.Code, loc)
+		}
+	}
+}
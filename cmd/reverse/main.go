@@ -0,0 +1,127 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command reverse tests patterns outside of a running application.
+//
+// It compiles a pattern, tests sample strings against it printing the
+// extracted variables, or reverts key=value pairs into a string:
+//
+//	reverse match '^/users/(?P<id>\d+)$' /users/42 /users/abc
+//	reverse -syntax gorilla revert '/users/{id:[0-9]+}' id=42
+//
+// Positional variables use an empty key, as in "=42".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/reverse"
+)
+
+const usage = `usage: reverse [flags] match PATTERN STRING...
+       reverse [flags] revert PATTERN KEY=VALUE...
+
+flags:
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// run runs the command with the given arguments, writing to w.
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("reverse", flag.ContinueOnError)
+	syntax := fs.String("syntax", "regexp",
+		"pattern syntax: regexp, gorilla (path) or gorilla-host")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 3 {
+		fs.Usage()
+		return fmt.Errorf("reverse: missing arguments")
+	}
+	cmd, pattern, rest := fs.Arg(0), fs.Arg(1), fs.Args()[2:]
+	re, err := compile(*syntax, pattern)
+	if err != nil {
+		return err
+	}
+	switch cmd {
+	case "match":
+		for _, s := range rest {
+			values := re.Values(s)
+			if values == nil {
+				fmt.Fprintf(w, "%s: no match\n", s)
+				continue
+			}
+			fmt.Fprintf(w, "%s: match %s\n", s, formatValues(values))
+		}
+	case "revert":
+		values := url.Values{}
+		for _, kv := range rest {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("reverse: invalid KEY=VALUE pair %q", kv)
+			}
+			values.Add(parts[0], parts[1])
+		}
+		s, err := re.RevertValid(values)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, s)
+	default:
+		return fmt.Errorf("reverse: unknown command %q", cmd)
+	}
+	return nil
+}
+
+// compile compiles a pattern using the given syntax.
+func compile(syntax, pattern string) (*reverse.Regexp, error) {
+	switch syntax {
+	case "regexp":
+		return reverse.CompileRegexp(pattern)
+	case "gorilla":
+		m, err := reverse.NewGorillaPath(pattern, false)
+		if err != nil {
+			return nil, err
+		}
+		return &m.Regexp, nil
+	case "gorilla-host":
+		m, err := reverse.NewGorillaHost(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &m.Regexp, nil
+	}
+	return nil, fmt.Errorf("reverse: unknown syntax %q", syntax)
+}
+
+// formatValues formats values as sorted key=value pairs.
+func formatValues(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range values[k] {
+			parts = append(parts, fmt.Sprintf("%s=%q", k, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
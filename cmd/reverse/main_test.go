@@ -0,0 +1,38 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect string
+	}{
+		{
+			[]string{"match", `^/users/(?P<id>\d+)$`, "/users/42", "/users/abc"},
+			"/users/42: match id=\"42\"\n/users/abc: no match\n",
+		},
+		{
+			[]string{"-syntax", "gorilla", "revert", "/users/{id:[0-9]+}", "id=42"},
+			"/users/42\n",
+		},
+	}
+	for _, v := range tests {
+		var buf bytes.Buffer
+		if err := run(v.args, &buf); err != nil {
+			t.Errorf("%v: %v", v.args, err)
+		} else if buf.String() != v.expect {
+			t.Errorf("%v: expected %q, got %q", v.args, v.expect, buf.String())
+		}
+	}
+	var buf bytes.Buffer
+	if err := run([]string{"revert", `^/(\d+)$`, "=a"}, &buf); err == nil {
+		t.Errorf("expected error reverting invalid value")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RouteConfig is a declarative route definition, converted to a RouteSpec.
+// Routes are loaded from a JSON list of definitions, for example:
+//
+//	[
+//		{"name": "user", "host": "{tenant}.example.com",
+//		 "path": "/users/{id:[0-9]+}", "methods": ["GET", "PUT"]},
+//		{"name": "static", "pathPrefix": "/static/"}
+//	]
+//
+// Only JSON is supported, so that the package has no dependencies; YAML
+// files can be converted to JSON before loading them.
+type RouteConfig struct {
+	Name       string            `json:"name"`
+	Schemes    []string          `json:"schemes,omitempty"`
+	Host       string            `json:"host,omitempty"`
+	PathPrefix string            `json:"pathPrefix,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	Methods    []string          `json:"methods,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Queries    map[string]string `json:"queries,omitempty"`
+}
+
+// RouteSpec returns the route definition. Its Err method returns an error
+// if one of the patterns is invalid.
+func (c RouteConfig) RouteSpec() *RouteSpec {
+	s := NewRouteSpec()
+	if len(c.Schemes) != 0 {
+		s.Schemes(c.Schemes...)
+	}
+	if c.Host != "" {
+		s.Host(c.Host)
+	}
+	if c.PathPrefix != "" {
+		s.PathPrefix(c.PathPrefix)
+	}
+	if c.Path != "" {
+		s.Path(c.Path)
+	}
+	if len(c.Methods) != 0 {
+		s.Methods(c.Methods...)
+	}
+	if len(c.Headers) != 0 {
+		s.Headers(pairsFromMap(c.Headers)...)
+	}
+	if len(c.Queries) != 0 {
+		s.Queries(pairsFromMap(c.Queries)...)
+	}
+	return s
+}
+
+// LoadRoutes decodes a JSON list of route definitions and returns a set of
+// their RouteSpecs, by name and in order. Names must be unique and not
+// empty.
+//
+// The set can be reloaded at runtime calling LoadRoutesInto.
+func LoadRoutes(data []byte) (*DynamicSet, error) {
+	s := NewDynamicSet()
+	if err := LoadRoutesInto(s, data); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadRoutesInto decodes a JSON list of route definitions and replaces the
+// matchers in the set with their RouteSpecs. The set is unchanged if there
+// is an error.
+func LoadRoutesInto(s *DynamicSet, data []byte) error {
+	var configs []RouteConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return err
+	}
+	entries := make([]dynamicEntry, len(configs))
+	seen := make(map[string]bool, len(configs))
+	for k, c := range configs {
+		if c.Name == "" {
+			return fmt.Errorf("Missing name for route %d", k)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("Multiple definitions for route %q", c.Name)
+		}
+		seen[c.Name] = true
+		spec := c.RouteSpec()
+		if err := spec.Err(); err != nil {
+			return fmt.Errorf("Invalid route %q: %v", c.Name, err)
+		}
+		entries[k] = dynamicEntry{c.Name, spec}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.Store(&entries)
+	return nil
+}
+
+// pairsFromMap converts a map to a list of key/value pairs, sorted by key.
+func pairsFromMap(m map[string]string) []string {
+	pairs := make([]string, 0, 2*len(m))
+	for _, k := range sortedKeys(m) {
+		pairs = append(pairs, k, m[k])
+	}
+	return pairs
+}
@@ -0,0 +1,72 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestLoadRoutes(t *testing.T) {
+	s, err := LoadRoutes([]byte(`[
+		{"name": "user", "host": "{tenant}.domain.com",
+		 "path": "/users/{id:[0-9]+}", "methods": ["GET", "PUT"],
+		 "headers": {"X-Requested-With": "XMLHttpRequest"}},
+		{"name": "static", "pathPrefix": "/static/", "queries": {"v": "{v}"}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := s.Names(); len(names) != 2 || names[0] != "user" || names[1] != "static" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	r, _ := http.NewRequest("GET", "http://acme.domain.com/users/42", nil)
+	testMatcher(t, "LoadRoutes", s, r, false)
+	r.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if name, _ := s.MatchName(r); name != "user" {
+		t.Errorf("expected user, got %q", name)
+	}
+	result := &Result{}
+	s.Extract(result, r)
+	expect := url.Values{"tenant": {"acme"}, "id": {"42"}}
+	if !equalValues(result.Values, expect) {
+		t.Errorf("expected values %v, got %v", expect, result.Values)
+	}
+
+	r, _ = http.NewRequest("GET", "http://domain.com/static/app.js?v=3", nil)
+	if name, _ := s.MatchName(r); name != "static" {
+		t.Errorf("expected static, got %q", name)
+	}
+
+	u := &url.URL{}
+	err = s.Get("user").(Builder).Build(u, url.Values{"tenant": {"acme"}, "id": {"7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str := u.String(); str != "http://acme.domain.com/users/7" {
+		t.Errorf("unexpected URL %s", str)
+	}
+}
+
+func TestLoadRoutesErrors(t *testing.T) {
+	tests := []string{
+		`{}`,
+		`[{"path": "/"}]`,
+		`[{"name": "a", "path": "/"}, {"name": "a", "path": "/b"}]`,
+		`[{"name": "a", "path": "/{id:[}"}]`,
+	}
+	s := NewDynamicSet()
+	s.Set("old", NewPath("/"))
+	for _, v := range tests {
+		if err := LoadRoutesInto(s, []byte(v)); err == nil {
+			t.Errorf("%s: expected an error", v)
+		}
+	}
+	if s.Len() != 1 || s.Get("old") == nil {
+		t.Errorf("the set was changed after an error")
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// ConflictKind is the kind of a conflict between two path patterns.
+type ConflictKind int
+
+const (
+	// ConflictOverlap means that some paths match both patterns, so the
+	// order of registration decides which one is used.
+	ConflictOverlap ConflictKind = iota
+	// ConflictShadowed means that all paths matching the second pattern
+	// also match the first one, so the second one is never used.
+	ConflictShadowed
+	// ConflictDuplicate means that both patterns match the same paths.
+	ConflictDuplicate
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictOverlap:
+		return "overlaps"
+	case ConflictShadowed:
+		return "shadows"
+	case ConflictDuplicate:
+		return "duplicates"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// Conflict is a pair of conflicting path patterns, identified by the index
+// of their matchers. First is registered before Second.
+type Conflict struct {
+	First         int
+	Second        int
+	FirstPattern  string
+	SecondPattern string
+	Kind          ConflictKind
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%q %s %q", c.FirstPattern, c.Kind, c.SecondPattern)
+}
+
+// FindConflicts compares the path patterns of the given matchers, in order,
+// and returns the pairs that overlap. Only static paths and Gorilla paths are
+// compared, using the first one found in each matcher; other matchers, and
+// patterns with optional variables or catch-all variables mixed with
+// literals, are skipped. Other parts of the matchers, like methods, are not
+// taken into account.
+//
+// Patterns are compared segment by segment. For variables with different
+// patterns it is a heuristic: they are assumed to overlap unless their
+// first characters differ, like `{id:[0-9]+}` and `{name:[a-z]+}`.
+func FindConflicts(matchers []Matcher) []Conflict {
+	patterns := make([]*conflictPattern, len(matchers))
+	for k, m := range matchers {
+		patterns[k] = newConflictPattern(m)
+	}
+	var conflicts []Conflict
+	for i, a := range patterns {
+		if a == nil {
+			continue
+		}
+		for j := i + 1; j < len(patterns); j++ {
+			b := patterns[j]
+			if b == nil || !a.intersects(b) {
+				continue
+			}
+			c := Conflict{First: i, Second: j, FirstPattern: a.source,
+				SecondPattern: b.source}
+			if a.covers(b) {
+				c.Kind = ConflictShadowed
+				if b.covers(a) {
+					c.Kind = ConflictDuplicate
+				}
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts
+}
+
+// Conflicts returns the conflicts between the paths of the registered
+// routes; see FindConflicts. The indices are the route priorities.
+func (r *Router) Conflicts() []Conflict {
+	matchers := make([]Matcher, len(r.routes))
+	for k, v := range r.routes {
+		matchers[k] = v.Matcher
+	}
+	return FindConflicts(matchers)
+}
+
+// conflictPattern is a path pattern split in segments.
+type conflictPattern struct {
+	source   string
+	segments []conflictSegment
+	catchAll bool // whether the last segment is a catch-all variable
+}
+
+// conflictSegment is a path segment of a pattern.
+type conflictSegment struct {
+	literal string         // for static segments
+	static  bool           // whether it has no variables
+	expr    string         // regexp without variable names
+	re      *regexp.Regexp // anchored expr
+	// catchAll is set for catch-all variables, which can only be last.
+	catchAll bool
+}
+
+// newConflictPattern returns the path pattern of a matcher, or nil.
+func newConflictPattern(m Matcher) *conflictPattern {
+	var source, defaultPattern string
+	var static bool
+	walkMatchers(m, func(m Matcher) {
+		if source != "" {
+			return
+		}
+		switch v := m.(type) {
+		case Path:
+			source, static = string(v), true
+		case *GorillaPath:
+			source, defaultPattern = v.pattern, v.opts.defaultPattern
+		}
+	})
+	if !strings.HasPrefix(source, "/") {
+		return nil
+	}
+	defaultPattern = gorillaDefault(defaultPattern, false)
+	p := &conflictPattern{source: source}
+	for _, s := range splitSegments(source[1:]) {
+		seg := conflictSegment{literal: s, static: true}
+		if !static {
+			var ok bool
+			if seg, ok = newConflictSegment(s, defaultPattern); !ok {
+				return nil
+			}
+		}
+		p.segments = append(p.segments, seg)
+		p.catchAll = seg.catchAll
+	}
+	return p
+}
+
+// newConflictSegment parses a segment of a Gorilla pattern. It returns
+// false for optional variables, and catch-all variables mixed with
+// literals.
+func newConflictSegment(s, defaultPattern string) (conflictSegment, bool) {
+	parts, err := ParseGorillaTemplate(s)
+	if err != nil {
+		return conflictSegment{}, false
+	}
+	seg := conflictSegment{literal: s, static: true}
+	var expr strings.Builder
+	for _, part := range parts {
+		if part.Pattern == "*" {
+			if len(parts) != 1 {
+				return conflictSegment{}, false
+			}
+			seg.catchAll = true
+		}
+		if part.Kind == LiteralSegment {
+			expr.WriteString(regexp.QuoteMeta(part.Literal))
+			continue
+		}
+		if part.Optional {
+			return conflictSegment{}, false
+		}
+		seg.static = false
+		expr.WriteString("(?:" + part.regexpPattern(defaultPattern) + ")")
+	}
+	if seg.static {
+		return seg, true
+	}
+	seg.expr = expr.String()
+	seg.re, err = regexp.Compile("^" + seg.expr + "$")
+	return seg, err == nil
+}
+
+// covers returns whether all paths matching q also match p.
+func (p *conflictPattern) covers(q *conflictPattern) bool {
+	n := p.fixed()
+	if p.catchAll {
+		// The catch-all variable matches the rest of the path, which must
+		// have at least one more segment, even if it is empty.
+		if len(q.segments) < len(p.segments) {
+			return false
+		}
+	} else if q.catchAll || len(q.segments) != n {
+		return false
+	}
+	for k := 0; k < n; k++ {
+		if !p.segments[k].covers(q.segments[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersects returns whether some paths may match both patterns.
+func (p *conflictPattern) intersects(q *conflictPattern) bool {
+	switch {
+	case p.catchAll && q.catchAll:
+	case p.catchAll:
+		if len(q.segments) < len(p.segments) {
+			return false
+		}
+	case q.catchAll:
+		if len(p.segments) < len(q.segments) {
+			return false
+		}
+	case len(p.segments) != len(q.segments):
+		return false
+	}
+	n := p.fixed()
+	if m := q.fixed(); m < n {
+		n = m
+	}
+	for k := 0; k < n; k++ {
+		if !p.segments[k].intersects(q.segments[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fixed returns the number of segments before the catch-all variable.
+func (p *conflictPattern) fixed() int {
+	if p.catchAll {
+		return len(p.segments) - 1
+	}
+	return len(p.segments)
+}
+
+// covers returns whether all values matching t also match s.
+func (s conflictSegment) covers(t conflictSegment) bool {
+	switch {
+	case t.static:
+		return s.matches(t.literal)
+	case s.static:
+		return false
+	}
+	return s.expr == t.expr || s.expr == "(?:[^/]+)" || s.expr == "(?:[^/]*)"
+}
+
+// intersects returns whether some values may match both segments.
+func (s conflictSegment) intersects(t conflictSegment) bool {
+	switch {
+	case t.static:
+		return s.matches(t.literal)
+	case s.static:
+		return t.matches(s.literal)
+	case s.covers(t), t.covers(s):
+		return true
+	}
+	a, okA := firstRunes(s.expr)
+	b, okB := firstRunes(t.expr)
+	return !okA || !okB || rangesIntersect(a, b)
+}
+
+// matches returns whether the segment matches a static value.
+func (s conflictSegment) matches(value string) bool {
+	if s.static {
+		return s.literal == value
+	}
+	return s.re.MatchString(value)
+}
+
+// firstRunes returns the ranges of the runes that can start a non-empty
+// string matching the expression, as pairs like in syntax.Regexp.Rune.
+func firstRunes(expr string) ([]rune, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	return syntaxFirstRunes(re.Simplify())
+}
+
+// syntaxFirstRunes returns the ranges of the runes that can start a string
+// matching the parsed expression. It returns false if it can't tell, for
+// example when the expression can match an empty string.
+func syntaxFirstRunes(re *syntax.Regexp) ([]rune, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 || re.Flags&syntax.FoldCase != 0 &&
+			unicode.SimpleFold(re.Rune[0]) != re.Rune[0] {
+			return nil, false
+		}
+		return []rune{re.Rune[0], re.Rune[0]}, true
+	case syntax.OpCharClass:
+		return re.Rune, true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []rune{0, unicode.MaxRune}, true
+	case syntax.OpCapture, syntax.OpPlus:
+		return syntaxFirstRunes(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return syntaxFirstRunes(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		if len(re.Sub) > 0 {
+			return syntaxFirstRunes(re.Sub[0])
+		}
+	case syntax.OpAlternate:
+		var ranges []rune
+		for _, sub := range re.Sub {
+			r, ok := syntaxFirstRunes(sub)
+			if !ok {
+				return nil, false
+			}
+			ranges = append(ranges, r...)
+		}
+		return ranges, true
+	}
+	return nil, false
+}
+
+// rangesIntersect returns whether two lists of rune ranges intersect.
+func rangesIntersect(a, b []rune) bool {
+	for i := 0; i+1 < len(a); i += 2 {
+		for j := 0; j+1 < len(b); j += 2 {
+			if a[i] <= b[j+1] && b[j] <= a[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFindConflicts(t *testing.T) {
+	tests := []struct {
+		a, b   Matcher
+		expect string // empty for no conflict
+	}{
+		{MustNewGorillaPath("/users/{id}", false), NewPath("/users/new"),
+			`"/users/{id}" shadows "/users/new"`},
+		{NewPath("/users/new"), MustNewGorillaPath("/users/{id}", false),
+			`"/users/new" overlaps "/users/{id}"`},
+		{MustNewGorillaPath("/users/{id}", false), MustNewGorillaPath("/users/{name}", false),
+			`"/users/{id}" duplicates "/users/{name}"`},
+		{MustNewGorillaPath("/users/{id:[0-9]+}", false), MustNewGorillaPath("/users/{name:[a-z]+}", false), ""},
+		{MustNewGorillaPath("/users/{id:[0-9]+}", false), MustNewGorillaPath("/users/{name:[a-z0-9]+}", false),
+			`"/users/{id:[0-9]+}" overlaps "/users/{name:[a-z0-9]+}"`},
+		{MustNewGorillaPath("/users/{id:[0-9]+}", false), NewPath("/users/new"), ""},
+		{MustNewGorillaPath("/users/{id}", false), MustNewGorillaPath("/users/{id}/posts", false), ""},
+		{MustNewGorillaPath("/static/{path:*}", false), MustNewGorillaPath("/static/css/{file}", false),
+			`"/static/{path:*}" shadows "/static/css/{file}"`},
+		{MustNewGorillaPath("/static/css/{file}", false), MustNewGorillaPath("/static/{path:*}", false),
+			`"/static/css/{file}" overlaps "/static/{path:*}"`},
+		{MustNewGorillaPath("/static/{path:*}", false), NewPath("/static"), ""},
+		{MustNewGorillaPath("/files/{name}.{ext:json|xml}", false), NewPath("/files/a.json"),
+			`"/files/{name}.{ext:json|xml}" shadows "/files/a.json"`},
+		{MustNewGorillaPath("/files/{name}.{ext:json|xml}", false), NewPath("/files/a.txt"), ""},
+		{MustNewGorillaPath("/{a}/{b}?", false), NewPath("/x"), ""},
+		{NewAll([]Matcher{NewMethod([]string{"GET"}), NewPath("/a")}),
+			NewAll([]Matcher{NewMethod([]string{"POST"}), NewPath("/a")}),
+			`"/a" duplicates "/a"`},
+	}
+	for _, v := range tests {
+		conflicts := FindConflicts([]Matcher{v.a, v.b})
+		var got string
+		if len(conflicts) == 1 {
+			got = conflicts[0].String()
+		} else if len(conflicts) > 1 {
+			t.Errorf("%v, %v: unexpected conflicts %v", v.a, v.b, conflicts)
+			continue
+		}
+		if got != v.expect {
+			t.Errorf("%v, %v: expected %q, got %q", v.a, v.b, v.expect, got)
+		}
+	}
+}
+
+func TestRouterConflicts(t *testing.T) {
+	router := NewRouter()
+	h := http.NotFoundHandler()
+	router.Handle("user", h, MustNewGorillaPath("/users/{id}", false))
+	router.Handle("users", h, NewPath("/users"))
+	router.Handle("new", h, NewPath("/users/new"))
+	conflicts := router.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].First != 0 || conflicts[0].Second != 2 ||
+		conflicts[0].Kind != ConflictShadowed {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+}
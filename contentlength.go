@@ -0,0 +1,43 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// NewContentLength returns a matcher for request bodies of at least min and
+// at most max bytes. A zero max means no limit.
+func NewContentLength(min, max int64) ContentLength {
+	return ContentLength{Min: min, Max: max}
+}
+
+// ContentLength matches the size of the request body, according to the
+// Content-Length header. Required rejects requests without a body.
+//
+// Requests of unknown length, like chunked ones, only match if Max is zero,
+// because their size can't be checked before reading them.
+type ContentLength struct {
+	Min      int64
+	Max      int64
+	Required bool
+}
+
+func (m ContentLength) Match(r *http.Request) bool {
+	n := r.ContentLength
+	if n < 0 {
+		// Unknown length.
+		return m.Max == 0
+	}
+	if m.Required && (n == 0 || r.Body == nil || r.Body == http.NoBody) {
+		return false
+	}
+	return n >= m.Min && (m.Max == 0 || n <= m.Max)
+}
+
+func (m ContentLength) String() string {
+	return fmt.Sprintf("ContentLength(%d, %d, %v)", m.Min, m.Max, m.Required)
+}
@@ -0,0 +1,44 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestContentLength(t *testing.T) {
+	const name = "ContentLength"
+	tests := []struct {
+		matcher ContentLength
+		body    string
+		length  int64
+		expect  bool
+	}{
+		{NewContentLength(0, 0), "", 0, true},
+		{NewContentLength(0, 10), "hello", 5, true},
+		{NewContentLength(0, 4), "hello", 5, false},
+		{NewContentLength(6, 0), "hello", 5, false},
+		{NewContentLength(0, 10), "hello", -1, false},
+		{NewContentLength(1, 0), "hello", -1, true},
+		{ContentLength{Required: true}, "", 0, false},
+		{ContentLength{Required: true}, "hello", 5, true},
+		{ContentLength{Required: true}, "hello", -1, true},
+	}
+	for _, v := range tests {
+		var body io.Reader
+		if v.body != "" {
+			body = strings.NewReader(v.body)
+		}
+		r, err := http.NewRequest("POST", "http://domain.com/upload", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.ContentLength = v.length
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+}
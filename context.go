@@ -0,0 +1,72 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// contextKey is the type of the keys used in request contexts.
+type contextKey int
+
+const (
+	// varsKey is the request context key for the route variables.
+	varsKey contextKey = iota
+	// resultKey is the request context key for the match result.
+	resultKey
+)
+
+// Vars returns the route variables stored in the request context by SetVars.
+func Vars(r *http.Request) url.Values {
+	v, _ := r.Context().Value(varsKey).(url.Values)
+	return v
+}
+
+// SetVars returns a shallow copy of the request with the route variables
+// stored in its context.
+func SetVars(r *http.Request, values url.Values) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), varsKey, values))
+}
+
+// MatchResult returns the match result stored in the request context by
+// SetResult, or nil. Router and RouteList store it before calling handlers,
+// so middlewares can read the matched route name and pattern:
+//
+//	if result := reverse.MatchResult(r); result != nil {
+//		log.Printf("%s %s", r.Method, result.Pattern)
+//	}
+func MatchResult(r *http.Request) *Result {
+	v, _ := r.Context().Value(resultKey).(*Result)
+	return v
+}
+
+// SetResult returns a shallow copy of the request with the match result and
+// its variables stored in its context.
+func SetResult(r *http.Request, result *Result) *http.Request {
+	ctx := context.WithValue(r.Context(), varsKey, result.Values)
+	return r.WithContext(context.WithValue(ctx, resultKey, result))
+}
+
+// MatchHandler returns a handler that matches requests using the given
+// matcher. When the matcher matches, the result and the variables it
+// extracts are stored in the request context and h is called, unless an
+// extractor set a different handler, like a redirect. Otherwise it replies
+// with 404.
+func MatchHandler(m Matcher, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := MatchExtract(m, r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if result.Handler == nil {
+			result.Handler = h
+		}
+		result.Matcher = m
+		result.Handler.ServeHTTP(w, SetResult(r, result))
+	})
+}
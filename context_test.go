@@ -0,0 +1,54 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSetVars(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://domain.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := Vars(r); v != nil {
+		t.Errorf("expected nil vars, got %v", v)
+	}
+	values := url.Values{"id": {"42"}}
+	if v := Vars(SetVars(r, values)); !equalValues(values, v) {
+		t.Errorf("expected %v, got %v", values, v)
+	}
+}
+
+func TestMatchHandler(t *testing.T) {
+	h := MatchHandler(MustNewGorillaPath("/users/{id:[0-9]+}", false),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "user %s", Vars(r).Get("id"))
+		}))
+	tests := []struct {
+		rURL string
+		code int
+		body string
+	}{
+		{"http://domain.com/users/42", http.StatusOK, "user 42"},
+		{"http://domain.com/users/abc", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != v.code || w.Body.String() != v.body {
+			t.Errorf("%s: expected %d %q, got %d %q", v.rURL, v.code, v.body,
+				w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+)
+
+// DebugRoute is a route listed by DebugHandler.
+type DebugRoute struct {
+	Name     string
+	Priority int
+	Matcher  Matcher
+}
+
+// DebugHandler returns a handler that renders the routes returned by the
+// given function, to inspect the live routing state; for example, at a
+// "/debug/routes" endpoint.
+//
+// The routes are rendered as JSON if the request has a "format=json" query
+// or accepts "application/json", and as HTML otherwise.
+func DebugHandler(routes func() []DebugRoute) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list := routes()
+		infos := make([]RouteDescription, len(list))
+		for k, v := range list {
+			infos[k] = Describe(v.Name, v.Matcher)
+			infos[k].Priority = v.Priority
+		}
+		if r.URL.Query().Get("format") == "json" ||
+			strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(infos)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugTemplate.Execute(w, infos)
+	})
+}
+
+// RouteDescription describes a route: the hosts, path patterns, methods and
+// variables found in its matcher. Prefix patterns end with "*".
+type RouteDescription struct {
+	Name      string   `json:"name,omitempty"`
+	Priority  int      `json:"priority"`
+	Matcher   string   `json:"matcher"`
+	Hosts     []string `json:"hosts,omitempty"`
+	Patterns  []string `json:"patterns,omitempty"`
+	Methods   []string `json:"methods,omitempty"`
+	Variables []string `json:"variables,omitempty"`
+	// Constraints are the patterns of the Gorilla host and path variables.
+	Constraints map[string]string `json:"constraints,omitempty"`
+}
+
+// Describe returns the description of a route with the given name and
+// matcher.
+func Describe(name string, matcher Matcher) RouteDescription {
+	info := RouteDescription{
+		Name:    name,
+		Matcher: fmt.Sprint(matcher),
+	}
+	walkMatchers(matcher, func(m Matcher) {
+		switch v := m.(type) {
+		case Host:
+			info.Hosts = append(info.Hosts, string(v))
+		case *RegexpHost:
+			info.Hosts = append(info.Hosts, v.compiled.String())
+		case *GorillaHost:
+			info.Hosts = append(info.Hosts, v.pattern)
+		case *WildcardHost:
+			info.Hosts = append(info.Hosts, v.pattern)
+		case Path:
+			info.Patterns = append(info.Patterns, string(v))
+		case PathPrefix:
+			info.Patterns = append(info.Patterns, string(v)+"*")
+		case PathRedirect:
+			info.Patterns = append(info.Patterns, string(v))
+		case PathRedirectCode:
+			info.Patterns = append(info.Patterns, string(v.PathRedirect))
+		case SlashPath:
+			info.Patterns = append(info.Patterns, string(v.Path))
+		case *RegexpPath:
+			info.Patterns = append(info.Patterns, v.compiled.String())
+		case *GorillaPath:
+			info.Patterns = append(info.Patterns, v.pattern)
+		case *GorillaPathPrefix:
+			info.Patterns = append(info.Patterns, v.pattern+"*")
+		case Method:
+			info.Methods = append(info.Methods, v...)
+		}
+		if g, ok := m.(interface{ Groups() []string }); ok {
+			info.Variables = append(info.Variables, g.Groups()...)
+		}
+		if g, ok := m.(interface {
+			VarNames() []string
+			VarPattern(string) string
+		}); ok {
+			for _, name := range g.VarNames() {
+				if info.Constraints == nil {
+					info.Constraints = map[string]string{}
+				}
+				info.Constraints[name] = g.VarPattern(name)
+			}
+		}
+	})
+	return info
+}
+
+// DumpRoutes writes the routes as an aligned text table, with their names,
+// methods, hosts, path patterns and variables with their constraints.
+func DumpRoutes(w io.Writer, routes []RouteDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMETHODS\tHOST\tPATH\tVARIABLES")
+	for _, v := range routes {
+		vars := make([]string, 0, len(v.Variables))
+		for _, name := range v.Variables {
+			if c, ok := v.Constraints[name]; ok {
+				name += ":" + c
+			}
+			vars = append(vars, name)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", dumpField(v.Name),
+			dumpField(v.Methods...), dumpField(v.Hosts...),
+			dumpField(v.Patterns...), dumpField(vars...))
+	}
+	return tw.Flush()
+}
+
+// dumpField formats a table field, using "-" if it is empty.
+func dumpField(values ...string) string {
+	if s := strings.Join(values, ","); s != "" {
+		return s
+	}
+	return "-"
+}
+
+var debugTemplate = htmltemplate.Must(htmltemplate.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Routes</title></head>
+<body>
+<table>
+<tr><th>Name</th><th>Priority</th><th>Hosts</th><th>Patterns</th><th>Methods</th><th>Variables</th><th>Matcher</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Priority}}</td><td>{{range .Hosts}}{{.}} {{end}}</td><td>{{range .Patterns}}{{.}} {{end}}</td><td>{{range .Methods}}{{.}} {{end}}</td><td>{{range .Variables}}{{.}} {{end}}</td><td><code>{{.Matcher}}</code></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,75 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	routes := func() []DebugRoute {
+		return []DebugRoute{{
+			Name:     "user",
+			Priority: 1,
+			Matcher:  NewRouteSpec().Host("{tenant}.domain.com").Path("/users/{id}").Methods("GET"),
+		}}
+	}
+	h := DebugHandler(routes)
+	r, err := http.NewRequest("GET", "http://domain.com/debug/routes?format=json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	var infos []RouteDescription
+	if err := json.Unmarshal(w.Body.Bytes(), &infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 route, got %d", len(infos))
+	}
+	info := infos[0]
+	if info.Name != "user" || !stringSliceEqual(info.Hosts, []string{"{tenant}.domain.com"}) ||
+		!stringSliceEqual(info.Patterns, []string{"/users/{id}"}) ||
+		!stringSliceEqual(info.Methods, []string{"GET"}) ||
+		!stringSliceEqual(info.Variables, []string{"tenant", "id"}) {
+		t.Errorf("unexpected route info: %+v", info)
+	}
+	r, err = http.NewRequest("GET", "http://domain.com/debug/routes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), "<td>user</td>") {
+		t.Errorf("expected HTML table, got %q", w.Body.String())
+	}
+}
+
+func TestRouterDump(t *testing.T) {
+	router := NewRouter()
+	h := http.NotFoundHandler()
+	router.Handle("user", h, NewMethod([]string{"GET", "PUT"}),
+		MustNewGorillaPath("/users/{id:[0-9]+}", false))
+	router.Handle("", h, NewPathPrefix("/static/"))
+	var b strings.Builder
+	if err := router.Dump(&b); err != nil {
+		t.Fatal(err)
+	}
+	expect := "NAME  METHODS  HOST  PATH                VARIABLES\n" +
+		"user  GET,PUT  -     /users/{id:[0-9]+}  id:[0-9]+\n" +
+		"-     -        -     /static/*           -\n"
+	if s := b.String(); s != expect {
+		t.Errorf("expected:\n%s\ngot:\n%s", expect, s)
+	}
+	routes := router.Describe()
+	if len(routes) != 2 || routes[1].Priority != 1 || routes[0].Constraints["id"] != "[0-9]+" {
+		t.Errorf("unexpected descriptions %+v", routes)
+	}
+}
@@ -40,6 +40,7 @@ although there are two capturing groups: re.Revert(url.Values{"": {"2", "a"}})
 results in "123" and not "12a3".
 
 2. Literals inside capturing groups are ignored; the whole group becomes
-a placeholder.
+a placeholder. The WithGroupLiterals option keeps them for groups made of
+literals around a single variable part, like `(user-\d+)`.
 */
 package reverse
@@ -0,0 +1,139 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// NewDynamicSet returns an empty dynamic set.
+func NewDynamicSet() *DynamicSet {
+	return &DynamicSet{}
+}
+
+// DynamicSet is a set of named matchers that can be changed at runtime, for
+// example to reload routes without restarting a server. Like One, it
+// matches if one of the matchers matches, trying them in the order they were
+// added.
+//
+// It is safe for concurrent use: changes replace an immutable snapshot of
+// the set, so requests being matched keep using the previous one. The zero
+// value is an empty set ready to use.
+type DynamicSet struct {
+	mu      sync.Mutex // serializes changes
+	entries atomic.Pointer[[]dynamicEntry]
+}
+
+// snapshot returns the current entries, which must not be modified.
+func (s *DynamicSet) snapshot() []dynamicEntry {
+	if p := s.entries.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// dynamicEntry is a named matcher in a DynamicSet.
+type dynamicEntry struct {
+	name    string
+	matcher Matcher
+}
+
+// Set adds a matcher with the given name, or replaces the one already added
+// with the name, keeping its position.
+func (s *DynamicSet) Set(name string, m Matcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.snapshot()
+	entries := make([]dynamicEntry, len(old), len(old)+1)
+	copy(entries, old)
+	for k, v := range entries {
+		if v.name == name {
+			entries[k].matcher = m
+			s.entries.Store(&entries)
+			return
+		}
+	}
+	entries = append(entries, dynamicEntry{name, m})
+	s.entries.Store(&entries)
+}
+
+// Remove removes the matcher with the given name. It returns false if there
+// was none.
+func (s *DynamicSet) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.snapshot()
+	for k, v := range old {
+		if v.name == name {
+			entries := make([]dynamicEntry, 0, len(old)-1)
+			entries = append(entries, old[:k]...)
+			entries = append(entries, old[k+1:]...)
+			s.entries.Store(&entries)
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the matcher with the given name, or nil.
+func (s *DynamicSet) Get(name string) Matcher {
+	for _, v := range s.snapshot() {
+		if v.name == name {
+			return v.matcher
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the matchers, in order.
+func (s *DynamicSet) Names() []string {
+	entries := s.snapshot()
+	names := make([]string, len(entries))
+	for k, v := range entries {
+		names[k] = v.name
+	}
+	return names
+}
+
+// Len returns the number of matchers.
+func (s *DynamicSet) Len() int {
+	return len(s.snapshot())
+}
+
+func (s *DynamicSet) Match(r *http.Request) bool {
+	_, m := s.MatchName(r)
+	return m != nil
+}
+
+// MatchName returns the name of the first matching matcher and the matcher,
+// or a nil matcher if none matches.
+func (s *DynamicSet) MatchName(r *http.Request) (string, Matcher) {
+	for _, v := range s.snapshot() {
+		if v.matcher.Match(r) {
+			return v.name, v.matcher
+		}
+	}
+	return "", nil
+}
+
+// Extract calls Extract on the first matching matcher.
+func (s *DynamicSet) Extract(result *Result, r *http.Request) {
+	if _, m := s.MatchName(r); m != nil {
+		extractMatcher(m, result, r)
+	}
+}
+
+func (s *DynamicSet) String() string {
+	entries := s.snapshot()
+	parts := make([]string, len(entries))
+	for k, v := range entries {
+		parts[k] = v.name + ": " + fmt.Sprint(v.matcher)
+	}
+	return "DynamicSet(" + strings.Join(parts, ", ") + ")"
+}
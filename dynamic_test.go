@@ -0,0 +1,91 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestDynamicSet(t *testing.T) {
+	s := NewDynamicSet()
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "DynamicSet", s, r, false)
+
+	s.Set("posts", MustNewGorillaPath("/posts/{id}", false))
+	s.Set("users", NewPath("/users"))
+	testMatcher(t, "DynamicSet", s, r, false)
+
+	s.Set("users", MustNewGorillaPath("/users/{id}", false))
+	testMatcher(t, "DynamicSet", s, r, true)
+	if name, _ := s.MatchName(r); name != "users" {
+		t.Errorf("expected users, got %q", name)
+	}
+	result := &Result{}
+	s.Extract(result, r)
+	if expect := (url.Values{"id": {"42"}}); !equalValues(result.Values, expect) {
+		t.Errorf("expected values %v, got %v", expect, result.Values)
+	}
+	if names := s.Names(); len(names) != 2 || names[0] != "posts" || names[1] != "users" {
+		t.Errorf("unexpected names %v", names)
+	}
+	expect := `DynamicSet(posts: GorillaPath("/posts/{id}"), users: GorillaPath("/users/{id}"))`
+	if str := s.String(); str != expect {
+		t.Errorf("expected %s, got %s", expect, str)
+	}
+
+	if !s.Remove("users") || s.Remove("users") {
+		t.Errorf("unexpected Remove result")
+	}
+	if s.Get("users") != nil || s.Len() != 1 {
+		t.Errorf("users was not removed")
+	}
+	testMatcher(t, "DynamicSet", s, r, false)
+}
+
+func TestDynamicSetZero(t *testing.T) {
+	var s DynamicSet
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "DynamicSet", &s, r, false)
+	if s.Get("users") != nil || s.Len() != 0 || s.Remove("users") {
+		t.Errorf("expected an empty set")
+	}
+	s.Set("users", MustNewGorillaPath("/users/{id}", false))
+	testMatcher(t, "DynamicSet", &s, r, true)
+}
+
+func TestDynamicSetConcurrent(t *testing.T) {
+	s := NewDynamicSet()
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Set("users", MustNewGorillaPath("/users/{id}", false))
+				s.Remove("users")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Extract(&Result{}, r)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Equal returns whether two matchers match exactly the same requests in the
+// same way, as far as it can tell.
+//
+// Built-in matchers are compared by their type and settings; order matters
+// for lists, so Method("GET", "POST") is not equal to Method("POST", "GET").
+// Other matchers, and composites that contain them, are never equal, even
+// to themselves; two nil matchers are equal.
+func Equal(a, b Matcher) bool {
+	ka, oka := matcherKey(a)
+	kb, okb := matcherKey(b)
+	if oka && okb {
+		return ka == kb
+	}
+	return a == nil && b == nil
+}
+
+// Hash returns a hash for a built-in matcher, consistent with Equal. It
+// returns false if the matcher, or one of the matchers it is composed of, is
+// not a built-in one.
+func Hash(m Matcher) (uint64, bool) {
+	key, ok := matcherKey(m)
+	if !ok {
+		return 0, false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64(), true
+}
+
+// matcherKey returns a string that identifies a built-in matcher.
+func matcherKey(m Matcher) (string, bool) {
+	switch v := m.(type) {
+	case All:
+		return compositeKey("All", v)
+	case One:
+		return compositeKey("One", v)
+	case Not:
+		return compositeKey("Not", []Matcher{v.Matcher})
+	case Header, Host, Method, None, *None, Always, Path, PathRedirect,
+		PathPrefix, Query, Scheme, ContentType, Accept, SmartMethod, PathFold,
+		PathPrefixFold, TLS, CleanPath, ContentLength, Upgrade, Proto,
+		RequireHTTPS, SlashPath, CanonicalHost, PathRedirectCode:
+		return fmt.Sprint(v), true
+	case *RegexpHost:
+		return fmt.Sprintf("RegexpHost\x00%s\x00%+v", v.compiled.String(),
+			v.opts.settings()), true
+	case *RegexpPath:
+		return fmt.Sprintf("RegexpPath\x00%s\x00%+v", v.compiled.String(),
+			v.opts.settings()), true
+	case *GorillaQuery:
+		return fmt.Sprintf("GorillaQuery\x00%s\x00%s\x00%+v", v.key,
+			v.compiled.String(), v.opts.settings()), true
+	case *RegexpQuery:
+		return fmt.Sprintf("RegexpQuery\x00%s\x00%s\x00%+v", v.key,
+			v.compiled.String(), v.opts.settings()), true
+	case *RegexpHeader:
+		return fmt.Sprintf("RegexpHeader\x00%s\x00%s\x00%+v", v.key,
+			v.compiled.String(), v.opts.settings()), true
+	case *GorillaHost:
+		return fmt.Sprintf("GorillaHost\x00%s\x00%s\x00%v\x00%+v", v.scheme,
+			v.compiled.String(), v.port, v.opts.settings()), true
+	case *HostPort:
+		return fmt.Sprintf("HostPort\x00%s\x00%+v", v.compiled.String(),
+			v.opts.settings()), true
+	case *WildcardHost:
+		return fmt.Sprintf("WildcardHost\x00%s\x00%+v", v.compiled.String(),
+			v.opts.settings()), true
+	case *GorillaPath:
+		return fmt.Sprintf("GorillaPath\x00%s\x00%+v\x00%v",
+			v.compiled.String(), v.opts.settings(), v.StripQuery), true
+	case *GorillaPathPrefix:
+		return fmt.Sprintf("GorillaPathPrefix\x00%s\x00%+v",
+			v.compiled.String(), v.opts.settings()), true
+	}
+	return "", false
+}
+
+// compositeKey returns a string that identifies a composite matcher.
+func compositeKey(name string, matchers []Matcher) (string, bool) {
+	keys := make([]string, len(matchers))
+	for k, v := range matchers {
+		key, ok := matcherKey(v)
+		if !ok {
+			return "", false
+		}
+		keys[k] = key
+	}
+	return name + "(" + strings.Join(keys, "\x01") + ")", true
+}
@@ -0,0 +1,48 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	f := Func(func(r *http.Request) bool { return true })
+	tests := []struct {
+		a, b   Matcher
+		expect bool
+	}{
+		{NewPath("/a"), NewPath("a"), true},
+		{NewPath("/a"), NewPathPrefix("/a"), false},
+		{NewHeader(map[string]string{"a": "1", "b": ""}), NewHeader(map[string]string{"B": "", "A": "1"}), true},
+		{MustNewGorillaPath("/{id}", false), MustNewGorillaPath("/{id}", false), true},
+		{MustNewGorillaPath("/{id}", false), MustNewGorillaPath("/{id}", true), false},
+		{MustNewGorillaPath("/{id}", false), MustNewGorillaPath("/{id}", false, WithCaseFold()), false},
+		{NewAll([]Matcher{NewPath("/a"), NewMethod([]string{"get"})}), NewAll([]Matcher{NewPath("/a"), NewMethod([]string{"GET"})}), true},
+		{NewAll([]Matcher{NewPath("/a")}), NewOne([]Matcher{NewPath("/a")}), false},
+		{MustNewRegexpHost(`^(?P<sub>[a-z]+)\.a\.com$`), MustNewRegexpHost(`^(?P<sub>[a-z]+)\.a\.com$`), true},
+		{MustNewRegexpHost(`^(?P<sub>[a-z]+)\.a\.com$`), MustNewRegexpHost(`^(?P<sub>[a-z]+)\.a\.com$`, WithEscaping(PathEscaping)), false},
+		{MustNewRegexpHeader("a", `^(?P<v>.+)$`), MustNewRegexpHeader("a", `^(?P<v>.+)$`, WithEscaping(QueryEscaping)), false},
+		{MustNewGorillaHost("{sub}.a.com"), MustNewGorillaHost("{sub}.a.com", WithGroupLiterals()), false},
+		{f, f, false},
+		{NewNot(f), NewNot(f), false},
+		{NewNot(NewPath("/a")), NewNot(NewPath("/a")), true},
+		{NewNone(), NewNone(), true},
+	}
+	for _, v := range tests {
+		if Equal(v.a, v.b) != v.expect {
+			t.Errorf("Equal(%v, %v): expected %v", v.a, v.b, v.expect)
+		}
+		ha, oka := Hash(v.a)
+		hb, okb := Hash(v.b)
+		if v.expect && (!oka || !okb || ha != hb) {
+			t.Errorf("Hash(%v), Hash(%v): expected equal hashes", v.a, v.b)
+		}
+	}
+	if _, ok := Hash(NewAll([]Matcher{f})); ok {
+		t.Errorf("Hash: expected no hash for custom matchers")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MatchError describes why a request didn't match.
+type MatchError struct {
+	// Matcher is the innermost matcher that failed.
+	Matcher Matcher
+	// Reason explains the failure, like `method "POST" not in ["GET"]`.
+	Reason string
+	// Errors are the failures of the members of a One.
+	Errors []*MatchError
+}
+
+func (e *MatchError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Matcher, e.Reason)
+}
+
+// MatchExplain matches a request like m.Match, and returns an error
+// identifying the failing matcher and the reason if it doesn't match.
+//
+// The members of All, One and Not are explained recursively, as well as the
+// matchers of a Route, RouteSpec or Hooked.
+func MatchExplain(m Matcher, r *http.Request) (bool, *MatchError) {
+	switch v := m.(type) {
+	case All:
+		for _, sub := range v {
+			if ok, err := MatchExplain(sub, r); !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case One:
+		var errs []*MatchError
+		for _, sub := range v {
+			ok, err := MatchExplain(sub, r)
+			if ok {
+				return true, nil
+			}
+			errs = append(errs, err)
+		}
+		return false, &MatchError{Matcher: m, Reason: "no matcher matched",
+			Errors: errs}
+	case Not:
+		if v.Matcher.Match(r) {
+			return false, &MatchError{Matcher: m,
+				Reason: "negated matcher matched"}
+		}
+		return true, nil
+	case *Route:
+		return MatchExplain(v.Matcher, r)
+	case *RouteSpec:
+		if err := v.Err(); err != nil {
+			return false, &MatchError{Matcher: m, Reason: err.Error()}
+		}
+		return MatchExplain(v.Matcher(), r)
+	case *Hooked:
+		return MatchExplain(v.Unwrap(), r)
+	}
+	if m.Match(r) {
+		return true, nil
+	}
+	return false, &MatchError{Matcher: m, Reason: explain(m, r)}
+}
+
+// explain returns the reason why a matcher didn't match.
+func explain(m Matcher, r *http.Request) string {
+	switch v := m.(type) {
+	case Method:
+		return fmt.Sprintf("method %q not in [%s]", r.Method, formatList(v))
+	case SmartMethod:
+		return fmt.Sprintf("method %q not in [%s]", r.Method,
+			formatList(v.Allowed()))
+	case Scheme:
+		return fmt.Sprintf("scheme %q not in [%s]", r.URL.Scheme,
+			formatList(v))
+	case Host:
+		return fmt.Sprintf("host %q is not %q", getHost(r), string(v))
+	case Path, PathRedirect, PathRedirectCode, PathPrefix, SlashPath:
+		return fmt.Sprintf("path %q doesn't match", r.URL.Path)
+	case Header:
+		return explainPairs("header", v, r.Header)
+	case Query:
+		return explainPairs("query parameter", v, r.URL.Query())
+	case None, *None:
+		return "never matches"
+	case *RegexpHost:
+		return fmt.Sprintf("host %q doesn't match the regexp", getHost(r))
+	case *GorillaHost:
+		if v.scheme != "" && v.scheme != requestScheme(r) {
+			return fmt.Sprintf("scheme %q is not %q", requestScheme(r),
+				v.scheme)
+		}
+		return fmt.Sprintf("host %q doesn't match the regexp", v.host(r))
+	case *HostPort:
+		return fmt.Sprintf("host %q doesn't match the regexp", hostPort(r))
+	case *WildcardHost:
+		return fmt.Sprintf("host %q is not under %q", getHost(r), v.pattern)
+	case *RegexpPath:
+		return fmt.Sprintf("path %q doesn't match the regexp", v.opts.path(r))
+	case *GorillaPath:
+		return fmt.Sprintf("path %q doesn't match the regexp", v.opts.path(r))
+	case *GorillaPathPrefix:
+		return fmt.Sprintf("path %q doesn't match the regexp", v.opts.path(r))
+	case *RegexpQuery:
+		return explainValues("query parameter", v.key, r.URL.Query()[v.key])
+	case *GorillaQuery:
+		return explainValues("query parameter", v.key, r.URL.Query()[v.key])
+	case *RegexpHeader:
+		return explainValues("header", v.key, r.Header[v.key])
+	case ContentType:
+		return fmt.Sprintf("content type %q not in [%s]",
+			r.Header.Get("Content-Type"), formatList(v))
+	case Accept:
+		return fmt.Sprintf("none of [%s] is acceptable for %q", formatList(v),
+			strings.Join(r.Header["Accept"], ", "))
+	}
+	return "no match"
+}
+
+// explainPairs returns the reason why a Header or Query didn't match.
+func explainPairs(kind string, m map[string]string, src map[string][]string) string {
+	for _, k := range sortedKeys(m) {
+		values, ok := src[k]
+		if !ok {
+			return fmt.Sprintf("missing %s %q", kind, k)
+		}
+		if v := m[k]; v != "" && !containsString(values, v) {
+			return fmt.Sprintf("%s %q is not %q", kind, k, v)
+		}
+	}
+	return "no match"
+}
+
+// explainValues returns the reason why a regexp matcher for a header or
+// query parameter didn't match.
+func explainValues(kind, key string, values []string) string {
+	if len(values) == 0 {
+		return fmt.Sprintf("missing %s %q", kind, key)
+	}
+	return fmt.Sprintf("%s %q values [%s] don't match the regexp", kind, key,
+		formatList(values))
+}
+
+// containsString returns whether a slice contains a string.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMatchExplain(t *testing.T) {
+	path := MustNewGorillaPath("/users/{id:[0-9]+}", false)
+	tests := []struct {
+		matcher Matcher
+		method  string
+		rURL    string
+		failed  Matcher
+		reason  string
+	}{
+		{
+			NewAll([]Matcher{NewMethod([]string{"GET"}), path}),
+			"GET", "http://domain.com/users/42", nil, "",
+		},
+		{
+			NewAll([]Matcher{NewMethod([]string{"GET"}), path}),
+			"POST", "http://domain.com/users/42",
+			NewMethod([]string{"GET"}), `method "POST" not in ["GET"]`,
+		},
+		{
+			NewAll([]Matcher{NewHost("domain.com"), path}),
+			"GET", "http://domain.com/users/abc",
+			path, `path "/users/abc" doesn't match the regexp`,
+		},
+		{
+			NewHost("other.com"), "GET", "http://domain.com/",
+			NewHost("other.com"), `host "domain.com" is not "other.com"`,
+		},
+		{
+			NewQuery(map[string]string{"a": "1", "b": ""}),
+			"GET", "http://domain.com/?a=1",
+			NewQuery(map[string]string{"a": "1", "b": ""}),
+			`missing query parameter "b"`,
+		},
+		{
+			NewNot(NewPath("/")), "GET", "http://domain.com/",
+			NewNot(NewPath("/")), "negated matcher matched",
+		},
+		{
+			MustNewRegexpQuery("page", `^\d+$`), "GET", "http://domain.com/?page=a",
+			MustNewRegexpQuery("page", `^\d+$`),
+			`query parameter "page" values ["a"] don't match the regexp`,
+		},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, merr := MatchExplain(v.matcher, r)
+		if ok != v.matcher.Match(r) {
+			t.Errorf("%v: MatchExplain disagrees with Match", v.matcher)
+		}
+		if v.failed == nil {
+			if !ok || merr != nil {
+				t.Errorf("%v: expected match, got %v", v.matcher, merr)
+			}
+			continue
+		}
+		if ok || merr == nil {
+			t.Errorf("%v: expected no match", v.matcher)
+			continue
+		}
+		if !Equal(merr.Matcher, v.failed) || merr.Reason != v.reason {
+			t.Errorf("%v: expected %v: %s, got %v", v.matcher, v.failed,
+				v.reason, merr)
+		}
+	}
+
+	one := NewOne([]Matcher{NewPath("/a"), NewPath("/b")})
+	r, _ := http.NewRequest("GET", "http://domain.com/c", nil)
+	ok, merr := MatchExplain(one, r)
+	if ok || merr == nil || len(merr.Errors) != 2 {
+		t.Errorf("expected One failure with 2 errors, got %v", merr)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Generate returns an example string matching the regexp, reverting it with
+// short values for all groups, like "a" for `[a-z]+` or "0" for `\d{1,3}`.
+// It is useful for tests, documentation and health checks.
+//
+// It returns an error if the generated string doesn't match, which can
+// happen with assertions like `\b`.
+func (r *Regexp) Generate() (string, error) {
+	return r.RevertValid(r.ExampleValues())
+}
+
+// ExampleValues returns short values matching the pattern of each group;
+// see Generate.
+func (r *Regexp) ExampleValues() url.Values {
+	values := url.Values{}
+	for k, v := range r.groups {
+		var b strings.Builder
+		generate(&b, r.patterns.parsed[k], nil)
+		values.Add(v, b.String())
+	}
+	return values
+}
@@ -0,0 +1,37 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		re     *Regexp
+		expect string
+	}{
+		{MustCompileRegexp(`^/users/(?P<id>\d+)$`), "/users/0"},
+		{MustCompileRegexp(`^/(?P<lang>[a-z]{2})/(\w+)\.html$`), "/aa/a.html"},
+		{MustCompileRegexp(`^/v(?P<v>[1-9])/(?P<tag>x|y)$`), "/v1/x"},
+		{MustCompileRegexp(`^/(?P<s>[^/]*)/(?P<a>.+)$`), "//a"},
+		{&MustNewGorillaPath("/articles/{category}/{id:int}", false).Regexp, "/articles/a/0"},
+		{&MustNewGorillaPath("/static/{path:*}", false).Regexp, "/static/"},
+		{&MustNewGorillaPath("/items/{id:uuid}", false).Regexp,
+			"/items/aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"},
+		{&MustNewGorillaHost("{sub:[A-Z]+}.domain.com").Regexp, "A.domain.com"},
+	}
+	for _, v := range tests {
+		s, err := v.re.Generate()
+		if err != nil {
+			t.Errorf("%s: %v", v.re.Compiled(), err)
+		} else if s != v.expect {
+			t.Errorf("%s: expected %q, got %q", v.re.Compiled(), v.expect, s)
+		}
+	}
+	if _, err := MustCompileRegexp(`^(?P<a>\w*)\b(?P<b>\w*)$`).Generate(); err == nil {
+		t.Errorf("expected an error for an invalid example")
+	}
+}
@@ -7,44 +7,195 @@ package reverse
 import (
 	"bytes"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // GorillaHost ----------------------------------------------------------------
 
-func NewGorillaHost(pattern string) (*GorillaHost, error) {
-	pattern, err := gorillaPattern(pattern, true, false, false)
+// NewGorillaHost returns a URL host matcher using Gorilla's special syntax
+// for named groups. The pattern can include a port, and be prefixed by a
+// scheme: "https://{sub}.domain.com:{port:[0-9]+}".
+func NewGorillaHost(pattern string, opts ...Option) (*GorillaHost, error) {
+	o := newOptions(opts)
+	host, scheme := pattern, ""
+	if i := strings.Index(host, "://"); i != -1 {
+		host, scheme = host[i+3:], strings.ToLower(host[:i])
+	}
+	regexpPattern, err := gorillaPattern(host, o.defaultPattern, true,
+		false, false)
 	if err != nil {
 		return nil, err
 	}
-	r, err := CompileRegexp(pattern)
+	r, err := o.compileGorilla(host, regexpPattern)
 	if err != nil {
 		return nil, err
 	}
-	return &GorillaHost{*r}, nil
+	return &GorillaHost{Regexp: *r, pattern: pattern, opts: o, scheme: scheme,
+		port: hasPort(host), vars: newGorillaVars(host, o.defaultPattern,
+			true)}, nil
+}
+
+// MustNewGorillaHost is like NewGorillaHost but panics if the pattern can't
+// be compiled.
+func MustNewGorillaHost(pattern string, opts ...Option) *GorillaHost {
+	m, err := NewGorillaHost(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewGorillaHost(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
 }
 
 // GorillaHost matches a URL host using Gorilla's special syntax for named
 // groups: `{name:regexp}`.
+//
+// If the pattern has a port, it is matched against "host:port", using the
+// default port for the scheme if the request has none. If the pattern has a
+// scheme, the request must use it too.
 type GorillaHost struct {
 	Regexp
+	pattern string
+	opts    options
+	scheme  string
+	port    bool
+	vars    gorillaVars
 }
 
 func (m *GorillaHost) Match(r *http.Request) bool {
-	return m.MatchString(getHost(r))
+	if m.scheme != "" && m.scheme != requestScheme(r) {
+		return false
+	}
+	return m.MatchString(m.host(r))
+}
+
+func (m *GorillaHost) MatchInfo(info *RequestInfo) bool {
+	if m.scheme != "" && m.scheme != requestScheme(info.Request) {
+		return false
+	}
+	if m.port {
+		return m.MatchString(info.HostPort())
+	}
+	return m.MatchString(info.Host())
+}
+
+func (m *GorillaHost) String() string {
+	return fmt.Sprintf("GorillaHost(%q)", m.pattern)
+}
+
+// VarNames returns the names of the variables in the pattern, in order.
+func (m *GorillaHost) VarNames() []string {
+	return m.vars.names
+}
+
+// VarPattern returns the regexp that the named variable must match, or an
+// empty string if there is no such variable.
+func (m *GorillaHost) VarPattern(name string) string {
+	return m.vars.patterns[name]
 }
 
 // Extract returns positional and named variables extracted from the URL host.
 func (m *GorillaHost) Extract(result *Result, r *http.Request) {
-	result.Values = mergeValues(result.Values, m.Values(getHost(r)))
+	result.Values = m.extractValues(result.Values, m.host(r))
+	if result.HostPattern == "" {
+		result.HostPattern = m.pattern
+	}
 }
 
 // Build builds the URL host using the given positional and named variables,
-// and writes it to the given URL.
+// and writes it to the given URL, with the pattern scheme if any.
 func (m *GorillaHost) Build(u *url.URL, values url.Values) error {
+	host, err := m.RevertValid(values)
+	if err == nil {
+		if m.scheme != "" {
+			u.Scheme = m.scheme
+		} else if u.Scheme == "" {
+			u.Scheme = "http"
+		}
+		u.Host = host
+	}
+	return err
+}
+
+// host returns the request host to be matched, with the port if the
+// pattern has one.
+func (m *GorillaHost) host(r *http.Request) string {
+	if m.port {
+		return hostPort(r)
+	}
+	return getHost(r)
+}
+
+// HostPort -------------------------------------------------------------------
+
+// PortKey is the variable name used by HostPort for the request port.
+const PortKey = "port"
+
+// NewHostPort returns a matcher for the URL host and port using Gorilla's
+// special syntax for named groups, like "{sub}.domain.com:{port:[0-9]+}".
+func NewHostPort(pattern string, opts ...Option) (*HostPort, error) {
+	o := newOptions(opts)
+	regexpPattern, err := gorillaPattern(pattern, o.defaultPattern, true,
+		false, false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := o.compileGorilla(pattern, regexpPattern)
+	if err != nil {
+		return nil, err
+	}
+	return &HostPort{Regexp: *r, pattern: pattern, opts: o}, nil
+}
+
+// MustNewHostPort is like NewHostPort but panics if the pattern can't be
+// compiled.
+func MustNewHostPort(pattern string, opts ...Option) *HostPort {
+	m, err := NewHostPort(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewHostPort(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// HostPort matches the URL host and port, formatted as "host:port". When the
+// request has no explicit port, the default one for the scheme is used.
+type HostPort struct {
+	Regexp
+	pattern string
+	opts    options
+}
+
+func (m *HostPort) Match(r *http.Request) bool {
+	return m.MatchString(hostPort(r))
+}
+
+func (m *HostPort) MatchInfo(info *RequestInfo) bool {
+	return m.MatchString(info.HostPort())
+}
+
+func (m *HostPort) String() string {
+	return fmt.Sprintf("HostPort(%q)", m.pattern)
+}
+
+// Extract returns positional and named variables extracted from the URL host
+// and port. The port is also extracted using PortKey, unless the pattern
+// defines a variable with that name.
+func (m *HostPort) Extract(result *Result, r *http.Request) {
+	values := m.Values(hostPort(r))
+	if _, ok := values[PortKey]; !ok {
+		values = mergeValues(values, url.Values{PortKey: {getPort(r)}})
+	}
+	result.Values = mergeValues(result.Values, values)
+}
+
+// Build builds the URL host and port using the given positional and named
+// variables, and writes it to the given URL.
+func (m *HostPort) Build(u *url.URL, values url.Values) error {
 	host, err := m.RevertValid(values)
 	if err == nil {
 		if u.Scheme == "" {
@@ -55,77 +206,208 @@ func (m *GorillaHost) Build(u *url.URL, values url.Values) error {
 	return err
 }
 
+// hasPort returns whether a Gorilla host pattern has a port, that is, a
+// colon outside of braces.
+func hasPort(pattern string) bool {
+	var level int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+		case ':':
+			if level == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestScheme returns the request scheme, from the URL or else from the
+// connection.
+func requestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return r.URL.Scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// hostPort returns the request host and port, joined.
+func hostPort(r *http.Request) string {
+	return net.JoinHostPort(getHost(r), getPort(r))
+}
+
 // GorillaPath ----------------------------------------------------------------
 
-func NewGorillaPath(pattern string, strictSlash bool) (*GorillaPath, error) {
-	regexpPattern, err := gorillaPattern(pattern, false, false, strictSlash)
+// NewGorillaPath returns a matcher for the given Gorilla path pattern.
+//
+// The strictSlash argument is the same as passing WithStrictSlash; options
+// are applied after it.
+func NewGorillaPath(pattern string, strictSlash bool, opts ...Option) (*GorillaPath, error) {
+	o := newOptions(append([]Option{WithStrictSlash(strictSlash)}, opts...))
+	slash := o.slash(pattern)
+	regexpPattern, err := gorillaPattern(pattern, o.defaultPattern, false,
+		false, slash != SlashStrict)
 	if err != nil {
 		return nil, err
 	}
-	r, err := CompileRegexp(regexpPattern)
+	r, err := o.compileGorilla(pattern, regexpPattern)
 	if err != nil {
 		return nil, err
 	}
-	return &GorillaPath{*r, pattern, strictSlash}, nil
+	return &GorillaPath{
+		Regexp:     *r,
+		StripQuery: o.stripQuery,
+		pattern:    pattern,
+		opts:       o,
+		slash:      slash,
+		vars:       newGorillaVars(pattern, o.defaultPattern, false),
+	}, nil
+}
+
+// MustNewGorillaPath is like NewGorillaPath but panics if the pattern can't
+// be compiled.
+func MustNewGorillaPath(pattern string, strictSlash bool, opts ...Option) *GorillaPath {
+	m, err := NewGorillaPath(pattern, strictSlash, opts...)
+	if err != nil {
+		panic(`reverse: NewGorillaPath(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
 }
 
 // GorillaPath matches a URL path using Gorilla's special syntax for named
 // groups: `{name:regexp}`.
+//
+// A variable followed by "?" is optional, together with the preceding path
+// segment: `/articles/{category}/{id:[0-9]+}?` matches "/articles/go" and
+// "/articles/go/42". The segment is omitted when building without its value.
+//
+// A catch-all variable, written `{name:*}` or `{name...}`, matches the rest
+// of the path across slashes: `/static/{path:*}`.
+//
+// When strictSlash is set, requests that differ only by the trailing slash
+// are redirected to the path with the same trailing slash as the pattern.
+// WithSlashPolicy sets other policies. StripQuery drops the URL query from
+// the redirect target.
 type GorillaPath struct {
 	Regexp
-	pattern     string
-	strictSlash bool
+	StripQuery bool
+	pattern    string
+	opts       options
+	slash      SlashPolicy
+	vars       gorillaVars
 }
 
 func (m *GorillaPath) Match(r *http.Request) bool {
-	return m.MatchString(r.URL.Path)
+	return m.MatchString(m.opts.path(r))
+}
+
+func (m *GorillaPath) String() string {
+	return fmt.Sprintf("GorillaPath(%q)", m.pattern)
+}
+
+// VarNames returns the names of the variables in the pattern, in order.
+func (m *GorillaPath) VarNames() []string {
+	return m.vars.names
+}
+
+// VarPattern returns the regexp that the named variable must match, or an
+// empty string if there is no such variable.
+func (m *GorillaPath) VarPattern(name string) string {
+	return m.vars.patterns[name]
 }
 
 // Extract returns positional and named variables extracted from the URL path.
 func (m *GorillaPath) Extract(result *Result, r *http.Request) {
-	result.Values = mergeValues(result.Values, m.Values(r.URL.Path))
-	if result.Handler == nil && m.strictSlash {
-		result.Handler = redirectPath(m.pattern, r)
+	result.Values = m.extractValues(result.Values, m.opts.path(r))
+	if result.Pattern == "" {
+		result.Pattern = m.pattern
+	}
+	if result.Handler == nil {
+		result.Handler = m.slash.redirect(r, m.StripQuery,
+			m.opts.redirectCode)
 	}
 }
 
 // Build builds the URL path using the given positional and named variables,
-// and writes it to the given URL.
+// and writes it to the given URL. The trailing slash is the canonical one
+// for the slash policy.
 func (m *GorillaPath) Build(u *url.URL, values url.Values) error {
 	path, err := m.RevertValid(values)
 	if err == nil {
-		u.Path = path
+		err = m.opts.setPath(u, m.slash.canonical(path, m.pattern))
 	}
 	return err
 }
 
 // GorillaPathPrefix ----------------------------------------------------------
 
-func NewGorillaPathPrefix(pattern string) (*GorillaPathPrefix, error) {
-	regexpPattern, err := gorillaPattern(pattern, false, true, false)
+func NewGorillaPathPrefix(pattern string, opts ...Option) (*GorillaPathPrefix, error) {
+	o := newOptions(opts)
+	regexpPattern, err := gorillaPattern(pattern, o.defaultPattern, false,
+		true, false)
 	if err != nil {
 		return nil, err
 	}
-	r, err := CompileRegexp(regexpPattern)
+	r, err := o.compileGorilla(pattern, regexpPattern)
 	if err != nil {
 		return nil, err
 	}
-	return &GorillaPathPrefix{*r}, nil
+	return &GorillaPathPrefix{Regexp: *r, pattern: pattern, opts: o,
+		vars: newGorillaVars(pattern, o.defaultPattern, false)}, nil
+}
+
+// MustNewGorillaPathPrefix is like NewGorillaPathPrefix but panics if the
+// pattern can't be compiled.
+func MustNewGorillaPathPrefix(pattern string, opts ...Option) *GorillaPathPrefix {
+	m, err := NewGorillaPathPrefix(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewGorillaPathPrefix(` + strconv.Quote(pattern) +
+			`): ` + err.Error())
+	}
+	return m
 }
 
 // GorillaPathPrefix matches a URL path prefix using Gorilla's special syntax
 // for named groups: `{name:regexp}`.
 type GorillaPathPrefix struct {
 	Regexp
+	pattern string
+	opts    options
+	vars    gorillaVars
 }
 
 func (m *GorillaPathPrefix) Match(r *http.Request) bool {
-	return m.MatchString(r.URL.Path)
+	return m.MatchString(m.opts.path(r))
+}
+
+func (m *GorillaPathPrefix) String() string {
+	return fmt.Sprintf("GorillaPathPrefix(%q)", m.pattern)
+}
+
+// VarNames returns the names of the variables in the pattern, in order.
+func (m *GorillaPathPrefix) VarNames() []string {
+	return m.vars.names
+}
+
+// VarPattern returns the regexp that the named variable must match, or an
+// empty string if there is no such variable.
+func (m *GorillaPathPrefix) VarPattern(name string) string {
+	return m.vars.patterns[name]
 }
 
 // Extract returns positional and named variables extracted from the URL path.
 func (m *GorillaPathPrefix) Extract(result *Result, r *http.Request) {
-	result.Values = mergeValues(result.Values, m.Values(r.URL.Path))
+	result.Values = m.extractValues(result.Values, m.opts.path(r))
+	if result.Pattern == "" {
+		result.Pattern = m.pattern
+	}
 }
 
 // Build builds the URL path using the given positional and named variables,
@@ -133,24 +415,174 @@ func (m *GorillaPathPrefix) Extract(result *Result, r *http.Request) {
 func (m *GorillaPathPrefix) Build(u *url.URL, values url.Values) error {
 	path, err := m.RevertValid(values)
 	if err == nil {
-		u.Path = path
+		err = m.opts.setPath(u, path)
 	}
 	return err
 }
 
+// GorillaQuery ---------------------------------------------------------------
+
+// NewGorillaQuery returns a matcher for the values of the given URL query
+// parameter using a Gorilla pattern, like `{page:[0-9]+}`. Variables without
+// a pattern match any value. Like NewRegexpQuery, it rejects WithEscaping.
+func NewGorillaQuery(key, pattern string, opts ...Option) (*GorillaQuery, error) {
+	o := newOptions(opts)
+	if err := o.checkQuery(); err != nil {
+		return nil, err
+	}
+	defaultPattern := o.defaultPattern
+	if defaultPattern == "" {
+		defaultPattern = ".*"
+	}
+	regexpPattern, err := gorillaPattern(pattern, defaultPattern, false,
+		false, false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := o.compileGorilla(pattern, regexpPattern)
+	if err != nil {
+		return nil, err
+	}
+	return &GorillaQuery{
+		RegexpQuery: RegexpQuery{Regexp: *r, key: key, opts: o},
+		pattern:     pattern,
+	}, nil
+}
+
+// MustNewGorillaQuery is like NewGorillaQuery but panics if the pattern
+// can't be compiled.
+func MustNewGorillaQuery(key, pattern string, opts ...Option) *GorillaQuery {
+	m, err := NewGorillaQuery(key, pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewGorillaQuery(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// GorillaQuery matches a URL query parameter using Gorilla's special syntax
+// for named groups: `{name:regexp}`. One of the parameter values must match.
+type GorillaQuery struct {
+	RegexpQuery
+	pattern string
+}
+
+func (m *GorillaQuery) String() string {
+	return fmt.Sprintf("GorillaQuery(%q, %q)", m.key, m.pattern)
+}
+
 // Helpers --------------------------------------------------------------------
 
-// gorillaPattern transforms a gorilla pattern into a regexp pattern.
-func gorillaPattern(tpl string, matchHost, prefixMatch, strictSlash bool) (string, error) {
+// SegmentKind is the kind of a Gorilla template segment.
+type SegmentKind int
+
+const (
+	// LiteralSegment is a literal part of a template.
+	LiteralSegment SegmentKind = iota
+	// VariableSegment is a variable, like `{id:[0-9]+}`.
+	VariableSegment
+)
+
+// Segment is a part of a Gorilla template, as returned by
+// ParseGorillaTemplate.
+type Segment struct {
+	Kind SegmentKind
+	// Literal is the text of a literal segment.
+	Literal string
+	// Name is the variable name.
+	Name string
+	// Pattern is the variable regexp: empty if it was not set, or "*" for
+	// catch-all variables.
+	Pattern string
+	// Optional tells if the variable was followed by "?", or has a default.
+	Optional bool
+	// Default is the default value, set after the name like
+	// `{page=1:[0-9]+}`. It is not set after the pattern, like
+	// `{page:[0-9]+=1}`, because "=" is a valid regexp character: there it
+	// is part of the pattern.
+	Default string
+}
+
+// ParseGorillaTemplate parses a template written in Gorilla's syntax, like
+// `/articles/{category}/{id:[0-9]+}`, into literal and variable segments.
+// Variables can have a default value after their name, like
+// `{page=1:[0-9]+}`; see Segment.
+func ParseGorillaTemplate(tpl string) ([]Segment, error) {
 	// Check if it is well-formed.
 	idxs, err := braceIndices(tpl)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	var segments []Segment
+	var end int
+	for i := 0; i < len(idxs); i += 2 {
+		if raw := tpl[end:idxs[i]]; raw != "" {
+			segments = append(segments,
+				Segment{Kind: LiteralSegment, Literal: raw})
+		}
+		end = idxs[i+1]
+		parts := strings.SplitN(tpl[idxs[i]+1:end-1], ":", 2)
+		// The default is part of the name, so it can't clash with the
+		// pattern.
+		name, def, hasDef := strings.Cut(parts[0], "=")
+		if hasDef && def == "" {
+			return nil, fmt.Errorf("missing default value in %q",
+				tpl[idxs[i]:end])
+		}
+		seg := Segment{Kind: VariableSegment, Name: name, Default: def,
+			Optional: hasDef}
+		if len(parts) == 2 {
+			seg.Pattern = parts[1]
+		} else if strings.HasSuffix(seg.Name, "...") {
+			seg.Name, seg.Pattern = seg.Name[:len(seg.Name)-3], "*"
+		}
+		// Name or pattern can't be empty.
+		if seg.Name == "" || (len(parts) == 2 && seg.Pattern == "") {
+			return nil, fmt.Errorf("missing name or pattern in %q",
+				tpl[idxs[i]:end])
+		}
+		if end < len(tpl) && tpl[end] == '?' {
+			seg.Optional = true
+			end++
+		}
+		segments = append(segments, seg)
+	}
+	if raw := tpl[end:]; raw != "" {
+		segments = append(segments, Segment{Kind: LiteralSegment, Literal: raw})
+	}
+	return segments, nil
+}
+
+// compileGorilla compiles a regexp pattern translated from a Gorilla
+// template, using the template default values.
+func (o options) compileGorilla(tpl, pattern string) (*Regexp, error) {
+	r, err := o.compile(pattern)
+	if err != nil {
+		return nil, err
 	}
-	// Now let's parse it.
-	defaultPattern := "[^/]+"
+	segments, _ := ParseGorillaTemplate(tpl)
+	for _, seg := range segments {
+		if seg.Default != "" {
+			if r.defaults == nil {
+				r.defaults = map[string]string{}
+			}
+			r.defaults[seg.Name] = seg.Default
+		}
+	}
+	return r, nil
+}
+
+// gorillaPattern transforms a gorilla pattern into a regexp pattern.
+//
+// Variables without a pattern use defaultPattern, or a default depending on
+// matchHost if it is empty. Catch-all variables match anything, and typed
+// variables use the pattern of their type. An optional
+// variable is optional together with the literal since the last separator.
+func gorillaPattern(tpl, defaultPattern string, matchHost, prefixMatch, strictSlash bool) (string, error) {
+	defaultPattern = gorillaDefault(defaultPattern, matchHost)
+	sep := "/"
 	if matchHost {
-		defaultPattern = "[^.]+"
+		sep = "."
 		prefixMatch, strictSlash = false, false
 	} else {
 		if prefixMatch {
@@ -160,29 +592,34 @@ func gorillaPattern(tpl string, matchHost, prefixMatch, strictSlash bool) (strin
 			tpl = tpl[:len(tpl)-1]
 		}
 	}
+	segments, err := ParseGorillaTemplate(tpl)
+	if err != nil {
+		return "", err
+	}
 	pattern := bytes.NewBufferString("^")
-	var end int
-	for i := 0; i < len(idxs); i += 2 {
-		// Set all values we are interested in.
-		raw := tpl[end:idxs[i]]
-		end = idxs[i+1]
-		parts := strings.SplitN(tpl[idxs[i]+1:end-1], ":", 2)
-		name := parts[0]
-		patt := defaultPattern
-		if len(parts) == 2 {
-			patt = parts[1]
+	var optional string
+	for k, seg := range segments {
+		if seg.Kind == LiteralSegment {
+			raw := seg.Literal
+			if k+1 < len(segments) && segments[k+1].Optional {
+				j := strings.LastIndex(raw, sep)
+				if j == -1 {
+					j = 0
+				}
+				raw, optional = raw[:j], raw[j:]
+			}
+			pattern.WriteString(regexp.QuoteMeta(raw))
+			continue
 		}
-		// Name or pattern can't be empty.
-		if name == "" || patt == "" {
-			return "", fmt.Errorf("missing name or pattern in %q",
-				tpl[idxs[i]:end])
+		patt := seg.regexpPattern(defaultPattern)
+		if seg.Optional {
+			fmt.Fprintf(pattern, "(?:%s(?P<%s>%s))?",
+				regexp.QuoteMeta(optional), seg.Name, patt)
+			optional = ""
+		} else {
+			fmt.Fprintf(pattern, "(?P<%s>%s)", seg.Name, patt)
 		}
-		// Build the regexp pattern.
-		fmt.Fprintf(pattern, "%s(?P<%s>%s)", regexp.QuoteMeta(raw), name, patt)
 	}
-	// Add the remaining.
-	raw := tpl[end:]
-	pattern.WriteString(regexp.QuoteMeta(raw))
 	if strictSlash {
 		pattern.WriteString("[/]?")
 	}
@@ -192,6 +629,52 @@ func gorillaPattern(tpl string, matchHost, prefixMatch, strictSlash bool) (strin
 	return pattern.String(), nil
 }
 
+// gorillaDefault returns the pattern for variables without one, depending on
+// matchHost if defaultPattern is empty.
+func gorillaDefault(defaultPattern string, matchHost bool) string {
+	if defaultPattern != "" {
+		return defaultPattern
+	}
+	if matchHost {
+		return "[^.]+"
+	}
+	return "[^/]+"
+}
+
+// regexpPattern returns the regexp pattern for a variable segment.
+func (seg Segment) regexpPattern(defaultPattern string) string {
+	switch seg.Pattern {
+	case "":
+		return defaultPattern
+	case "*":
+		return ".*"
+	}
+	if t, ok := lookupVarType(seg.Pattern); ok {
+		return t.pattern
+	}
+	return seg.Pattern
+}
+
+// gorillaVars are the variables of a Gorilla pattern.
+type gorillaVars struct {
+	names    []string
+	patterns map[string]string // regexp pattern by name
+}
+
+// newGorillaVars returns the variables of a valid Gorilla pattern.
+func newGorillaVars(tpl, defaultPattern string, matchHost bool) gorillaVars {
+	defaultPattern = gorillaDefault(defaultPattern, matchHost)
+	segments, _ := ParseGorillaTemplate(tpl)
+	vars := gorillaVars{patterns: map[string]string{}}
+	for _, seg := range segments {
+		if seg.Kind == VariableSegment {
+			vars.names = append(vars.names, seg.Name)
+			vars.patterns[seg.Name] = seg.regexpPattern(defaultPattern)
+		}
+	}
+	return vars
+}
+
 // braceIndices returns the first level curly brace indices from a string.
 // It returns an error in case of unbalanced braces.
 func braceIndices(s string) ([]int, error) {
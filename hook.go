@@ -0,0 +1,104 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Hook receives notifications when instrumented matchers and builders run.
+// It can be used for tracing, timing or counting.
+//
+// Hooks are installed using Instrument or RouteSpec.Hook.
+type Hook interface {
+	// OnMatchStart is called before a matcher is called.
+	OnMatchStart(m Matcher, r *http.Request)
+	// OnMatchEnd is called after a matcher is called, with its result and
+	// the time it took.
+	OnMatchEnd(m Matcher, r *http.Request, matched bool, elapsed time.Duration)
+	// OnBuild is called after a builder is called, with its result.
+	OnBuild(b Builder, u *url.URL, err error)
+}
+
+// ExtractHook can be implemented by a Hook to be notified when variables
+// are extracted.
+type ExtractHook interface {
+	// OnExtract is called after an extractor is called, with the result.
+	OnExtract(e Extractor, r *http.Request, result *Result)
+}
+
+// Instrument returns a matcher that calls the hook when the given matcher
+// is used. The members of All and One are instrumented too, so the hook is
+// called for every matcher in the tree.
+func Instrument(m Matcher, h Hook) Matcher {
+	switch v := m.(type) {
+	case All:
+		m = All(instrumentList(v, h))
+	case One:
+		m = One(instrumentList(v, h))
+	}
+	return &Hooked{matcher: m, hook: h}
+}
+
+// instrumentList instruments a list of matchers.
+func instrumentList(matchers []Matcher, h Hook) []Matcher {
+	rv := make([]Matcher, len(matchers))
+	for k, v := range matchers {
+		rv[k] = Instrument(v, h)
+	}
+	return rv
+}
+
+// Hooked ----------------------------------------------------------------------
+
+// Hooked wraps a matcher and calls a hook when it is used. It is returned
+// by Instrument.
+//
+// It is also an Extractor and a Builder if the wrapped matcher is one.
+type Hooked struct {
+	matcher Matcher
+	hook    Hook
+}
+
+// Unwrap returns the wrapped matcher.
+func (m *Hooked) Unwrap() Matcher {
+	return m.matcher
+}
+
+func (m *Hooked) Match(r *http.Request) bool {
+	m.hook.OnMatchStart(m.matcher, r)
+	start := time.Now()
+	matched := m.matcher.Match(r)
+	m.hook.OnMatchEnd(m.matcher, r, matched, time.Since(start))
+	return matched
+}
+
+func (m *Hooked) String() string {
+	return fmt.Sprint(m.matcher)
+}
+
+// Extract calls the wrapped matcher Extract method, if it has one.
+func (m *Hooked) Extract(result *Result, r *http.Request) {
+	if e, ok := m.matcher.(Extractor); ok {
+		e.Extract(result, r)
+		if h, ok := m.hook.(ExtractHook); ok {
+			h.OnExtract(e, r, result)
+		}
+	}
+}
+
+// Build calls the wrapped matcher Build method, if it has one.
+func (m *Hooked) Build(u *url.URL, values url.Values) error {
+	b, ok := m.matcher.(Builder)
+	if !ok {
+		return nil
+	}
+	err := b.Build(u, values)
+	m.hook.OnBuild(b, u, err)
+	return err
+}
@@ -0,0 +1,97 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testHook struct {
+	events []string
+}
+
+func (h *testHook) OnMatchStart(m Matcher, r *http.Request) {
+	h.events = append(h.events, fmt.Sprintf("start %v", m))
+}
+
+func (h *testHook) OnMatchEnd(m Matcher, r *http.Request, matched bool, elapsed time.Duration) {
+	h.events = append(h.events, fmt.Sprintf("end %v %v", m, matched))
+}
+
+func (h *testHook) OnBuild(b Builder, u *url.URL, err error) {
+	h.events = append(h.events, fmt.Sprintf("build %v %v", b, err))
+}
+
+func TestInstrument(t *testing.T) {
+	h := &testHook{}
+	m := Instrument(NewAll([]Matcher{NewPath("/a"), NewMethod([]string{"POST"})}), h)
+	r, err := http.NewRequest("GET", "http://domain.com/a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "Instrument", m, r, false)
+	expect := []string{
+		`start All(Path("/a"), Method("POST"))`,
+		`start Path("/a")`,
+		`end Path("/a") true`,
+		`start Method("POST")`,
+		`end Method("POST") false`,
+		`end All(Path("/a"), Method("POST")) false`,
+	}
+	if !stringSliceEqual(expect, h.events) {
+		t.Errorf("expected %q, got %q", expect, h.events)
+	}
+
+	h = &testHook{}
+	spec := NewRouteSpec().Path("/{id}").Hook(h)
+	u := url.URL{}
+	if err := spec.Build(&u, url.Values{"id": {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	expect = []string{`build All(GorillaPath("/{id}")) <nil>`}
+	if !stringSliceEqual(expect, h.events) {
+		t.Errorf("expected %q, got %q", expect, h.events)
+	}
+}
+
+type testMetrics struct {
+	matches map[MetricLabels]int
+	builds  map[MetricLabels]int
+}
+
+func (m *testMetrics) ObserveMatch(labels MetricLabels, matched bool, elapsed time.Duration) {
+	if matched {
+		m.matches[labels]++
+	}
+}
+
+func (m *testMetrics) ObserveBuild(labels MetricLabels, err error) {
+	m.builds[labels]++
+}
+
+func TestMetricsHook(t *testing.T) {
+	metrics := &testMetrics{map[MetricLabels]int{}, map[MetricLabels]int{}}
+	spec := NewRouteSpec().Path("/{id}").Hook(NewMetricsHook("item", metrics))
+	r, err := http.NewRequest("GET", "http://domain.com/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.Match(r)
+	spec.Match(r)
+	if err := spec.Build(&url.URL{}, url.Values{"id": {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	labels := MetricLabels{Route: "item", Matcher: `All(GorillaPath("/{id}"))`}
+	if metrics.matches[labels] != 2 {
+		t.Errorf("expected 2 matches, got %d", metrics.matches[labels])
+	}
+	if metrics.builds[labels] != 1 {
+		t.Errorf("expected 1 build, got %d", metrics.builds[labels])
+	}
+}
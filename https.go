@@ -0,0 +1,51 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NewRequireHTTPS returns a matcher for requests not using HTTPS, which
+// redirects with 301 (http.StatusMovedPermanently).
+func NewRequireHTTPS() RequireHTTPS {
+	return RequireHTTPS{Code: http.StatusMovedPermanently}
+}
+
+// RequireHTTPS matches requests that don't use HTTPS, according to the URL
+// scheme or the connection. Extract sets a redirect to the same URL with
+// the https scheme and without the port; a zero Code uses 301.
+//
+// Register it before other routes so that they are only served over HTTPS:
+//
+//	router.Handle("", nil, reverse.NewRequireHTTPS())
+type RequireHTTPS struct {
+	Code int
+}
+
+func (m RequireHTTPS) Match(r *http.Request) bool {
+	return requestScheme(r) != "https"
+}
+
+func (m RequireHTTPS) String() string {
+	return fmt.Sprintf("RequireHTTPS(%d)", m.Code)
+}
+
+// Extract sets a redirect handler to the https URL.
+func (m RequireHTTPS) Extract(result *Result, r *http.Request) {
+	if result.Handler != nil || !m.Match(r) {
+		return
+	}
+	code := redirectCode(m.Code)
+	u := *r.URL
+	u.Scheme, u.Host = "https", getHost(r)
+	if strings.Contains(u.Host, ":") {
+		// IPv6 literal.
+		u.Host = "[" + u.Host + "]"
+	}
+	result.Handler = http.RedirectHandler(u.String(), code)
+}
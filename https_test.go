@@ -0,0 +1,115 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRequireHTTPS(t *testing.T) {
+	m := NewRequireHTTPS()
+	tests := []struct {
+		target   string
+		tls      bool
+		location string
+	}{
+		{"http://domain.com/a?b=c", false, "https://domain.com/a?b=c"},
+		{"http://domain.com:8080/a", false, "https://domain.com/a"},
+		{"http://[::1]:8080/a", false, "https://[::1]/a"},
+		{"/a", true, ""},
+		{"https://domain.com/a", false, ""},
+	}
+	for _, v := range tests {
+		r := httptest.NewRequest("GET", v.target, nil)
+		if v.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		testMatcher(t, "RequireHTTPS", m, r, v.location != "")
+		result := &Result{}
+		m.Extract(result, r)
+		if v.location == "" {
+			if result.Handler != nil {
+				t.Errorf("%s: unexpected redirect", v.target)
+			}
+			continue
+		}
+		w := httptest.NewRecorder()
+		result.Handler.ServeHTTP(w, r)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != v.location {
+			t.Errorf("%s: expected redirect to %s, got %d %s", v.target, v.location,
+				w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestSchemeBuild(t *testing.T) {
+	scheme := NewScheme([]string{"HTTPS", "http"})
+	host := MustNewGorillaHost("example.com")
+	path := MustNewGorillaPath("/users/{id}", false)
+	tests := []struct {
+		b      Builder
+		expect string
+	}{
+		{NewURLBuilder(scheme, path), "/users/42"},
+		{NewURLBuilder(host, scheme, path), "https://example.com/users/42"},
+		{NewRouteSpec().Schemes("https").Path("/users/{id}"), "/users/42"},
+		{NewRouteSpec().Schemes("https").Host("example.com").Path("/users/{id}"),
+			"https://example.com/users/42"},
+	}
+	for _, v := range tests {
+		u := &url.URL{}
+		if err := v.b.Build(u, url.Values{"id": {"42"}}); err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != v.expect {
+			t.Errorf("%v: expected %q, got %q", v.b, v.expect, u.String())
+		}
+	}
+}
+
+func TestCanonicalHost(t *testing.T) {
+	tests := []struct {
+		m        CanonicalHost
+		target   string
+		tls      bool
+		location string
+	}{
+		{NewCanonicalHost("example.com", "https"), "http://www.example.com/a?b=c", false, "https://example.com/a?b=c"},
+		{NewCanonicalHost("example.com", "https"), "http://example.com/a", false, "https://example.com/a"},
+		{NewCanonicalHost("example.com", "https"), "https://EXAMPLE.com/a", false, ""},
+		{NewCanonicalHost("example.com", "https"), "/a", false, "https://example.com/a"},
+		{NewCanonicalHost("example.com", "https"), "/a", true, ""},
+		{NewCanonicalHost("www.example.com", ""), "http://example.com:8080/a", false, "http://www.example.com/a"},
+		{NewCanonicalHost("www.example.com", ""), "https://www.example.com/a", false, ""},
+		{CanonicalHost{Host: "example.com:8443", Scheme: "https", Code: http.StatusPermanentRedirect},
+			"https://example.com/a", false, "https://example.com:8443/a"},
+	}
+	for _, v := range tests {
+		r := httptest.NewRequest("POST", v.target, nil)
+		if v.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		testMatcher(t, "CanonicalHost", v.m, r, v.location != "")
+		result := &Result{}
+		v.m.Extract(result, r)
+		if v.location == "" {
+			if result.Handler != nil {
+				t.Errorf("%s: unexpected redirect", v.target)
+			}
+			continue
+		}
+		w := httptest.NewRecorder()
+		result.Handler.ServeHTTP(w, r)
+		code := v.m.Code
+		if w.Code != code || w.Header().Get("Location") != v.location {
+			t.Errorf("%s: expected %d redirect to %s, got %d %s", v.target, code,
+				v.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,779 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Matchers are serialized as a JSON object with the matcher type and its
+// value, for example:
+//
+//	{"type": "All", "value": [
+//		{"type": "Method", "value": ["GET"]},
+//		{"type": "GorillaPath", "value": {"pattern": "/{id}", "strictSlash": false}}
+//	]}
+//
+// UnmarshalMatcher decodes them back using a registry of decoders per type.
+// Custom matchers can be registered using RegisterMatcher.
+
+// MatcherDecoder decodes the value of a serialized matcher.
+type MatcherDecoder func(value json.RawMessage) (Matcher, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]MatcherDecoder{}
+)
+
+// RegisterMatcher registers a decoder for the given matcher type. It
+// replaces any decoder previously registered for the type.
+func RegisterMatcher(typ string, decoder MatcherDecoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[typ] = decoder
+}
+
+// UnmarshalMatcher decodes a serialized matcher using the registered
+// decoders.
+func UnmarshalMatcher(data []byte) (Matcher, error) {
+	var j jsonMatcher
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	decodersMu.RLock()
+	decoder, ok := decoders[j.Type]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown matcher type %q", j.Type)
+	}
+	return decoder(j.Value)
+}
+
+// jsonMatcher is the serialized form of a matcher.
+type jsonMatcher struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// jsonGorillaPath is the serialized value of a GorillaPath.
+type jsonGorillaPath struct {
+	Pattern     string       `json:"pattern"`
+	StrictSlash bool         `json:"strictSlash"`
+	SlashPolicy SlashPolicy  `json:"slashPolicy,omitempty"`
+	StripQuery  bool         `json:"stripQuery,omitempty"`
+	Options     *jsonOptions `json:"options,omitempty"`
+}
+
+// jsonPattern is the serialized value of a host or path matcher with
+// options. It is serialized as the pattern alone if there are no options.
+type jsonPattern struct {
+	Pattern string       `json:"pattern"`
+	Options *jsonOptions `json:"options,omitempty"`
+}
+
+// jsonOptions is the serialized form of the options set on a matcher.
+type jsonOptions struct {
+	CaseFold       bool            `json:"caseFold,omitempty"`
+	LowerCase      bool            `json:"lowerCase,omitempty"`
+	Encoded        bool            `json:"encoded,omitempty"`
+	StripQuery     bool            `json:"stripQuery,omitempty"`
+	GroupLiterals  bool            `json:"groupLiterals,omitempty"`
+	DefaultPattern string          `json:"defaultPattern,omitempty"`
+	RedirectCode   int             `json:"redirectCode,omitempty"`
+	Duplicates     DuplicatePolicy `json:"duplicates,omitempty"`
+	Escaping       Escaping        `json:"escaping,omitempty"`
+	StrictTemplate bool            `json:"strictTemplate,omitempty"`
+}
+
+// jsonCanonicalHost is the serialized value of a CanonicalHost.
+type jsonCanonicalHost struct {
+	Host   string `json:"host"`
+	Scheme string `json:"scheme,omitempty"`
+	Code   int    `json:"code,omitempty"`
+}
+
+// jsonSlashPath is the serialized value of a SlashPath.
+type jsonSlashPath struct {
+	Path   string      `json:"path"`
+	Policy SlashPolicy `json:"policy"`
+	Code   int         `json:"code,omitempty"`
+}
+
+// jsonPathRedirectCode is the serialized value of a PathRedirectCode.
+type jsonPathRedirectCode struct {
+	Path string `json:"path"`
+	Code int    `json:"code,omitempty"`
+}
+
+// jsonQuery is the serialized value of a query parameter matcher.
+type jsonQuery struct {
+	Key     string       `json:"key"`
+	Pattern string       `json:"pattern"`
+	Options *jsonOptions `json:"options,omitempty"`
+}
+
+// jsonHeader is the serialized value of a RegexpHeader.
+type jsonHeader struct {
+	Key     string       `json:"key"`
+	Pattern string       `json:"pattern"`
+	Options *jsonOptions `json:"options,omitempty"`
+}
+
+// jsonTLS is the serialized value of a TLS matcher.
+type jsonTLS struct {
+	MinVersion uint16 `json:"minVersion,omitempty"`
+	ClientCert bool   `json:"clientCert,omitempty"`
+}
+
+// jsonContentLength is the serialized value of a ContentLength.
+type jsonContentLength struct {
+	Min      int64 `json:"min,omitempty"`
+	Max      int64 `json:"max,omitempty"`
+	Required bool  `json:"required,omitempty"`
+}
+
+// jsonRemoteAddr is the serialized value of a RemoteAddr.
+type jsonRemoteAddr struct {
+	Ranges            []string `json:"ranges"`
+	TrustProxyHeaders bool     `json:"trustProxyHeaders,omitempty"`
+}
+
+// jsonMount is the serialized value of a Mount.
+type jsonMount struct {
+	Prefix  string          `json:"prefix"`
+	Matcher json.RawMessage `json:"matcher"`
+}
+
+// newJSONOptions returns the serialized form of the options, or nil if
+// they are the defaults. The slash options are serialized by GorillaPath
+// itself. It returns an error for options that can't be serialized.
+func newJSONOptions(o options) (*jsonOptions, error) {
+	if o.vars != nil {
+		return nil, errors.New("reverse: can't serialize a matcher with a variable registry")
+	}
+	if o.customFlags {
+		return nil, errors.New("reverse: can't serialize a matcher with custom syntax flags")
+	}
+	j := jsonOptions{o.caseFold, o.lowerCase, o.encoded, o.stripQuery,
+		o.groupLiterals, o.defaultPattern, o.redirectCode, o.duplicates,
+		o.escaping, o.strictTemplate}
+	if j.RedirectCode == http.StatusMovedPermanently {
+		j.RedirectCode = 0
+	}
+	if j == (jsonOptions{}) {
+		return nil, nil
+	}
+	return &j, nil
+}
+
+// options returns the options to create a matcher with.
+func (j *jsonOptions) options() []Option {
+	if j == nil {
+		return nil
+	}
+	var opts []Option
+	if j.CaseFold {
+		opts = append(opts, WithCaseFold())
+	}
+	if j.LowerCase {
+		opts = append(opts, WithLowerCase())
+	}
+	if j.Encoded {
+		opts = append(opts, WithEncoding())
+	}
+	if j.StripQuery {
+		opts = append(opts, WithStripQuery())
+	}
+	if j.GroupLiterals {
+		opts = append(opts, WithGroupLiterals())
+	}
+	if j.DefaultPattern != "" {
+		opts = append(opts, WithDefaultPattern(j.DefaultPattern))
+	}
+	if j.RedirectCode != 0 {
+		opts = append(opts, WithRedirectCode(j.RedirectCode))
+	}
+	if j.StrictTemplate {
+		opts = append(opts, WithStrictTemplate())
+	}
+	return append(opts, WithDuplicates(j.Duplicates),
+		WithEscaping(j.Escaping))
+}
+
+// marshalPattern returns the serialized form of a host or path matcher
+// with options.
+func marshalPattern(typ, pattern string, o options) ([]byte, error) {
+	j, err := newJSONOptions(o)
+	if err != nil {
+		return nil, err
+	}
+	if j == nil {
+		return marshalMatcher(typ, pattern)
+	}
+	return marshalMatcher(typ, jsonPattern{pattern, j})
+}
+
+// unmarshalPattern decodes the value of a host or path matcher serialized
+// by marshalPattern.
+func unmarshalPattern(value json.RawMessage) (jsonPattern, error) {
+	var j jsonPattern
+	if err := json.Unmarshal(value, &j.Pattern); err == nil {
+		return j, nil
+	}
+	err := json.Unmarshal(value, &j)
+	return j, err
+}
+
+// regexpSource returns the pattern a regexp matcher was compiled from,
+// without the flags added by the options.
+func regexpSource(r *Regexp, o options) string {
+	pattern := r.compiled.String()
+	if o.caseFold {
+		pattern = strings.TrimPrefix(pattern, "(?i)")
+	}
+	return pattern
+}
+
+// marshalMatcher returns the serialized form of a matcher.
+func marshalMatcher(typ string, value interface{}) ([]byte, error) {
+	j := jsonMatcher{Type: typ}
+	if value != nil {
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		j.Value = v
+	}
+	return json.Marshal(j)
+}
+
+// unmarshalMatchers decodes a serialized list of matchers.
+func unmarshalMatchers(value json.RawMessage) ([]Matcher, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(value, &list); err != nil {
+		return nil, err
+	}
+	matchers := make([]Matcher, len(list))
+	for k, v := range list {
+		m, err := UnmarshalMatcher(v)
+		if err != nil {
+			return nil, err
+		}
+		matchers[k] = m
+	}
+	return matchers, nil
+}
+
+// unmarshalComposite decodes a serialized All or One.
+func unmarshalComposite(data []byte, typ string) ([]Matcher, error) {
+	var j jsonMatcher
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	if j.Type != typ {
+		return nil, fmt.Errorf("Expected matcher type %q, got %q", typ, j.Type)
+	}
+	return unmarshalMatchers(j.Value)
+}
+
+// Marshaling ----------------------------------------------------------------
+
+func (m All) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("All", []Matcher(m))
+}
+
+func (m *All) UnmarshalJSON(data []byte) error {
+	matchers, err := unmarshalComposite(data, "All")
+	if err == nil {
+		*m = All(matchers)
+	}
+	return err
+}
+
+func (m One) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("One", []Matcher(m))
+}
+
+func (m *One) UnmarshalJSON(data []byte) error {
+	matchers, err := unmarshalComposite(data, "One")
+	if err == nil {
+		*m = One(matchers)
+	}
+	return err
+}
+
+func (m Not) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Not", m.Matcher)
+}
+
+func (m Header) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Header", map[string]string(m))
+}
+
+func (m Host) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Host", string(m))
+}
+
+func (m Method) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Method", []string(m))
+}
+
+func (m None) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("None", nil)
+}
+
+func (m Always) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Always", nil)
+}
+
+func (m Path) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Path", string(m))
+}
+
+func (m PathRedirect) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("PathRedirect", string(m))
+}
+
+func (m PathPrefix) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("PathPrefix", string(m))
+}
+
+func (m Query) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Query", map[string]string(m))
+}
+
+func (m Scheme) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Scheme", []string(m))
+}
+
+func (m *RegexpHost) MarshalJSON() ([]byte, error) {
+	return marshalPattern("RegexpHost", regexpSource(&m.Regexp, m.opts), m.opts)
+}
+
+func (m *RegexpPath) MarshalJSON() ([]byte, error) {
+	return marshalPattern("RegexpPath", regexpSource(&m.Regexp, m.opts), m.opts)
+}
+
+func (m *RegexpQuery) MarshalJSON() ([]byte, error) {
+	opts, err := newJSONOptions(m.opts)
+	if err != nil {
+		return nil, err
+	}
+	return marshalMatcher("RegexpQuery", jsonQuery{m.key,
+		regexpSource(&m.Regexp, m.opts), opts})
+}
+
+func (m *GorillaHost) MarshalJSON() ([]byte, error) {
+	return marshalPattern("GorillaHost", m.pattern, m.opts)
+}
+
+func (m *GorillaPath) MarshalJSON() ([]byte, error) {
+	opts, err := newJSONOptions(m.opts)
+	if err != nil {
+		return nil, err
+	}
+	return marshalMatcher("GorillaPath", jsonGorillaPath{
+		Pattern:     m.pattern,
+		StrictSlash: m.opts.strictSlash,
+		SlashPolicy: m.opts.slashPolicy,
+		StripQuery:  m.StripQuery,
+		Options:     opts,
+	})
+}
+
+func (m *GorillaQuery) MarshalJSON() ([]byte, error) {
+	opts, err := newJSONOptions(m.opts)
+	if err != nil {
+		return nil, err
+	}
+	return marshalMatcher("GorillaQuery", jsonQuery{m.key, m.pattern, opts})
+}
+
+func (m *GorillaPathPrefix) MarshalJSON() ([]byte, error) {
+	return marshalPattern("GorillaPathPrefix", m.pattern, m.opts)
+}
+
+func (m *RegexpHeader) MarshalJSON() ([]byte, error) {
+	opts, err := newJSONOptions(m.opts)
+	if err != nil {
+		return nil, err
+	}
+	return marshalMatcher("RegexpHeader", jsonHeader{m.key,
+		regexpSource(&m.Regexp, m.opts), opts})
+}
+
+func (m *HostPort) MarshalJSON() ([]byte, error) {
+	return marshalPattern("HostPort", m.pattern, m.opts)
+}
+
+func (m PathRedirectCode) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("PathRedirectCode", jsonPathRedirectCode{
+		string(m.PathRedirect), m.Code})
+}
+
+func (m CanonicalHost) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("CanonicalHost", jsonCanonicalHost(m))
+}
+
+func (m SlashPath) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("SlashPath", jsonSlashPath{string(m.Path), m.Policy,
+		m.Code})
+}
+
+func (m *WildcardHost) MarshalJSON() ([]byte, error) {
+	return marshalPattern("WildcardHost", m.pattern, m.opts)
+}
+
+func (m PathFold) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("PathFold", string(m))
+}
+
+func (m PathPrefixFold) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("PathPrefixFold", string(m))
+}
+
+func (m ContentType) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("ContentType", []string(m))
+}
+
+func (m Accept) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Accept", []string(m))
+}
+
+func (m SmartMethod) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("SmartMethod", []string(m))
+}
+
+func (m TLS) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("TLS", jsonTLS{m.MinVersion, m.ClientCert})
+}
+
+func (m CleanPath) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("CleanPath", m.Code)
+}
+
+func (m RequireHTTPS) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("RequireHTTPS", m.Code)
+}
+
+func (m ContentLength) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("ContentLength", jsonContentLength{m.Min, m.Max,
+		m.Required})
+}
+
+func (m Upgrade) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Upgrade", string(m))
+}
+
+func (m Proto) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("Proto", []string(m))
+}
+
+func (m *RemoteAddr) MarshalJSON() ([]byte, error) {
+	return marshalMatcher("RemoteAddr", jsonRemoteAddr{m.ranges,
+		m.TrustProxyHeaders})
+}
+
+func (m *Mount) MarshalJSON() ([]byte, error) {
+	v, err := json.Marshal(m.Matcher)
+	if err != nil {
+		return nil, err
+	}
+	return marshalMatcher("Mount", jsonMount{m.Prefix.pattern, v})
+}
+
+// MarshalJSON serializes the route as its composed matcher, an All. It
+// returns the route error, if any.
+func (s *RouteSpec) MarshalJSON() ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.all.MarshalJSON()
+}
+
+// Decoders -------------------------------------------------------------------
+
+func init() {
+	RegisterMatcher("All", func(v json.RawMessage) (Matcher, error) {
+		matchers, err := unmarshalMatchers(v)
+		return NewAll(matchers), err
+	})
+	RegisterMatcher("One", func(v json.RawMessage) (Matcher, error) {
+		matchers, err := unmarshalMatchers(v)
+		return NewOne(matchers), err
+	})
+	RegisterMatcher("Not", func(v json.RawMessage) (Matcher, error) {
+		m, err := UnmarshalMatcher(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewNot(m), nil
+	})
+	RegisterMatcher("Header", func(v json.RawMessage) (Matcher, error) {
+		var m map[string]string
+		err := json.Unmarshal(v, &m)
+		return NewHeader(m), err
+	})
+	RegisterMatcher("Host", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewHost(s), err
+	})
+	RegisterMatcher("Method", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewMethod(s), err
+	})
+	RegisterMatcher("None", func(v json.RawMessage) (Matcher, error) {
+		return NewNone(), nil
+	})
+	RegisterMatcher("Always", func(v json.RawMessage) (Matcher, error) {
+		return NewAlways(), nil
+	})
+	RegisterMatcher("Path", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewPath(s), err
+	})
+	RegisterMatcher("PathRedirect", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewPathRedirect(s), err
+	})
+	RegisterMatcher("PathPrefix", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewPathPrefix(s), err
+	})
+	RegisterMatcher("Query", func(v json.RawMessage) (Matcher, error) {
+		var m map[string]string
+		err := json.Unmarshal(v, &m)
+		return NewQuery(m), err
+	})
+	RegisterMatcher("Scheme", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewScheme(s), err
+	})
+	RegisterMatcher("RegexpHost", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewRegexpHost(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("RegexpPath", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewRegexpPath(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("RegexpQuery", func(v json.RawMessage) (Matcher, error) {
+		var j jsonQuery
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m, err := NewRegexpQuery(j.Key, j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("GorillaHost", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewGorillaHost(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("GorillaPath", func(v json.RawMessage) (Matcher, error) {
+		var j jsonGorillaPath
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m, err := NewGorillaPath(j.Pattern, j.StrictSlash,
+			append([]Option{WithSlashPolicy(j.SlashPolicy)},
+				j.Options.options()...)...)
+		if err != nil {
+			return nil, err
+		}
+		m.StripQuery = j.StripQuery
+		return m, nil
+	})
+	RegisterMatcher("GorillaQuery", func(v json.RawMessage) (Matcher, error) {
+		var j jsonQuery
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m, err := NewGorillaQuery(j.Key, j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("GorillaPathPrefix", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewGorillaPathPrefix(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("RegexpHeader", func(v json.RawMessage) (Matcher, error) {
+		var j jsonHeader
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m, err := NewRegexpHeader(j.Key, j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("HostPort", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewHostPort(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("PathRedirectCode", func(v json.RawMessage) (Matcher, error) {
+		var j jsonPathRedirectCode
+		err := json.Unmarshal(v, &j)
+		return NewPathRedirectCode(j.Path, j.Code), err
+	})
+	RegisterMatcher("CanonicalHost", func(v json.RawMessage) (Matcher, error) {
+		var j jsonCanonicalHost
+		err := json.Unmarshal(v, &j)
+		return CanonicalHost(j), err
+	})
+	RegisterMatcher("SlashPath", func(v json.RawMessage) (Matcher, error) {
+		var j jsonSlashPath
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m := NewSlashPath(j.Path, j.Policy)
+		m.Code = j.Code
+		return m, nil
+	})
+	RegisterMatcher("WildcardHost", func(v json.RawMessage) (Matcher, error) {
+		j, err := unmarshalPattern(v)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewWildcardHost(j.Pattern, j.Options.options()...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+	RegisterMatcher("PathFold", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewPathFold(s), err
+	})
+	RegisterMatcher("PathPrefixFold", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewPathPrefixFold(s), err
+	})
+	RegisterMatcher("ContentType", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewContentType(s), err
+	})
+	RegisterMatcher("Accept", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewAccept(s), err
+	})
+	RegisterMatcher("SmartMethod", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewSmartMethod(s), err
+	})
+	RegisterMatcher("TLS", func(v json.RawMessage) (Matcher, error) {
+		var j jsonTLS
+		err := json.Unmarshal(v, &j)
+		return TLS{MinVersion: j.MinVersion, ClientCert: j.ClientCert}, err
+	})
+	RegisterMatcher("CleanPath", func(v json.RawMessage) (Matcher, error) {
+		var code int
+		err := json.Unmarshal(v, &code)
+		return CleanPath{Code: code}, err
+	})
+	RegisterMatcher("RequireHTTPS", func(v json.RawMessage) (Matcher, error) {
+		var code int
+		err := json.Unmarshal(v, &code)
+		return RequireHTTPS{Code: code}, err
+	})
+	RegisterMatcher("ContentLength", func(v json.RawMessage) (Matcher, error) {
+		var j jsonContentLength
+		err := json.Unmarshal(v, &j)
+		return ContentLength{Min: j.Min, Max: j.Max, Required: j.Required}, err
+	})
+	RegisterMatcher("Upgrade", func(v json.RawMessage) (Matcher, error) {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return NewUpgrade(s), err
+	})
+	RegisterMatcher("Proto", func(v json.RawMessage) (Matcher, error) {
+		var s []string
+		err := json.Unmarshal(v, &s)
+		return NewProto(s), err
+	})
+	RegisterMatcher("RemoteAddr", func(v json.RawMessage) (Matcher, error) {
+		var j jsonRemoteAddr
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		m, err := NewRemoteAddr(j.Ranges...)
+		if err != nil {
+			return nil, err
+		}
+		m.TrustProxyHeaders = j.TrustProxyHeaders
+		return m, nil
+	})
+	RegisterMatcher("Mount", func(v json.RawMessage) (Matcher, error) {
+		var j jsonMount
+		if err := json.Unmarshal(v, &j); err != nil {
+			return nil, err
+		}
+		sub, err := UnmarshalMatcher(j.Matcher)
+		if err != nil {
+			return nil, err
+		}
+		m, err := NewMount(j.Prefix, sub)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	})
+}
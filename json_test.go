@@ -0,0 +1,167 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	gorillaPath, err := NewGorillaPath("/{id:[0-9]+}/", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regexpHost, err := NewRegexpHost(`^(?P<sub>[a-z]+)\.domain\.com$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mount, err := NewMount("/api", MustNewGorillaPath("/users/{id}", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchers := []Matcher{
+		NewPath("/foo"),
+		NewHost("domain.com"),
+		NewMethod([]string{"GET", "POST"}),
+		NewHeader(map[string]string{"Accept": "text/html"}),
+		NewQuery(map[string]string{"q": ""}),
+		NewNone(),
+		NewNot(NewPath("/a")),
+		gorillaPath,
+		regexpHost,
+		MustNewGorillaQuery("page", "{page:[0-9]+}"),
+		MustNewRegexpHeader("accept", `^application/vnd\.api\.v(?P<version>\d+)\+json$`),
+		MustNewHostPort("{host}:8080"),
+		MustNewWildcardHost("**.example.com"),
+		NewSlashPath("/about", SlashRedirectToSlash),
+		NewPathRedirectCode("/form/", 308),
+		MustNewGorillaPath("/users/{id}", false, WithSlashPolicy(SlashIgnore)),
+		NewPathFold("/About"),
+		NewPathPrefixFold("/Static/"),
+		NewContentType([]string{"application/json"}),
+		NewAccept([]string{"text/html", "application/json"}),
+		NewSmartMethod([]string{"GET"}),
+		TLS{MinVersion: tls.VersionTLS12, ClientCert: true},
+		NewCleanPath(),
+		NewWebSocket(),
+		NewRequireHTTPS(),
+		NewCanonicalHost("example.com", "https"),
+		NewProto([]string{"HTTP/2"}),
+		ContentLength{Max: 1 << 20, Required: true},
+		&RemoteAddr{TrustProxyHeaders: true, ranges: []string{"10.0.0.0/8"}},
+		mount,
+		NewAll([]Matcher{NewScheme([]string{"https"}), NewOne([]Matcher{NewPathPrefix("/a"), NewAlways()})}),
+	}
+	for _, m := range matchers {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Errorf("%v: %v", m, err)
+			continue
+		}
+		decoded, err := UnmarshalMatcher(data)
+		if err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		if fmt.Sprint(m) != fmt.Sprint(decoded) {
+			t.Errorf("expected %v, got %v", m, decoded)
+		}
+	}
+	spec := NewRouteSpec().Path("/users/{id}").Methods("GET")
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := UnmarshalMatcher(data); err != nil || !Equal(decoded, spec.Matcher()) {
+		t.Errorf("%s: expected %v, got %v (%v)", data, spec.Matcher(), decoded, err)
+	}
+	var all All
+	data = []byte(`{"type":"All","value":[{"type":"Path","value":"/a"}]}`)
+	if err := json.Unmarshal(data, &all); err != nil {
+		t.Fatal(err)
+	}
+	if s := all.String(); s != `All(Path("/a"))` {
+		t.Errorf("expected %s, got %s", `All(Path("/a"))`, s)
+	}
+	if _, err := UnmarshalMatcher([]byte(`{"type":"Unknown"}`)); err == nil {
+		t.Errorf("expected error for unknown matcher type")
+	}
+}
+
+func TestJSONOptions(t *testing.T) {
+	matchers := []Matcher{
+		MustNewGorillaPath("/foo", false, WithCaseFold()),
+		MustNewGorillaPath("/{name}", false, WithEscaping(PathEscaping), WithDefaultPattern("[a-z]+")),
+		MustNewGorillaPath("/a/", true, WithRedirectCode(308), WithStripQuery()),
+		MustNewRegexpPath(`^/foo$`, WithCaseFold()),
+		MustNewGorillaPathPrefix("/static/", WithEncoding()),
+		NewRouteSpec().Options(WithCaseFold()).Path("/foo"),
+		MustNewRegexpHost(`^(?P<sub>[a-z]+)\.domain\.com$`, WithEscaping(PathEscaping)),
+		MustNewGorillaHost("{sub}.domain.com", WithDefaultPattern("[a-z]+")),
+		MustNewHostPort("{host}:{port}", WithGroupLiterals()),
+		MustNewWildcardHost("*.example.com", WithStrictTemplate()),
+		MustNewRegexpQuery("q", `^(?P<q>[a-z]+)$`, WithCaseFold()),
+		MustNewGorillaQuery("page", "{page}", WithDefaultPattern("[0-9]+")),
+		MustNewRegexpHeader("accept", `^text/(?P<sub>[a-z]+)$`, WithCaseFold()),
+	}
+	for _, m := range matchers {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Errorf("%v: %v", m, err)
+			continue
+		}
+		decoded, err := UnmarshalMatcher(data)
+		if err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		expect := m
+		if spec, ok := m.(*RouteSpec); ok {
+			expect = spec.Matcher()
+		}
+		if !Equal(expect, decoded) {
+			t.Errorf("%s: expected %v, got %v", data, expect, decoded)
+		}
+		if again, err := json.Marshal(decoded); err != nil || string(again) != string(data) {
+			t.Errorf("%s: round trip gave %s (%v)", data, again, err)
+		}
+	}
+	data, err := json.Marshal(NewRouteSpec().Options(WithCaseFold()).Path("/foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalMatcher(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := http.NewRequest("GET", "http://domain.com/FOO", nil)
+	testMatcher(t, "RouteSpec", decoded, r, true)
+	data, err = json.Marshal(MustNewRegexpHeader("accept", `^text/html$`, WithCaseFold()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err = UnmarshalMatcher(data); err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Accept", "TEXT/HTML")
+	testMatcher(t, "RegexpHeader", decoded, r, true)
+
+	errors := []Matcher{
+		NewRouteSpec().Path("/{id:(}").Methods("GET"),
+		MustNewGorillaPath("/{id}", false, WithVarRegistry(NewVarRegistry())),
+		MustNewGorillaHost("{sub}.domain.com", WithVarRegistry(NewVarRegistry())),
+		MustNewRegexpQuery("q", `^(?P<q>.+)$`, WithVarRegistry(NewVarRegistry())),
+		MustNewRegexpHeader("accept", `^(?P<a>.+)$`, WithVarRegistry(NewVarRegistry())),
+	}
+	for _, m := range errors {
+		if data, err := json.Marshal(m); err == nil {
+			t.Errorf("%v: expected error, got %s", m, data)
+		}
+	}
+}
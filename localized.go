@@ -0,0 +1,117 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// LocaleKey is the variable name used by LocalizedRoute for the locale.
+const LocaleKey = "locale"
+
+// NewLocalizedRoute returns a route with one Gorilla path pattern per locale,
+// for example:
+//
+//	reverse.NewLocalizedRoute(map[string]string{
+//		"en": "/en/products/{id}",
+//		"de": "/de/produkte/{id}",
+//	}, "en")
+//
+// The default locale is used to build URLs when no locale is given; it
+// must be one of the keys. Options are passed to NewGorillaPath.
+func NewLocalizedRoute(patterns map[string]string, defaultLocale string, opts ...Option) (*LocalizedRoute, error) {
+	if _, ok := patterns[defaultLocale]; !ok {
+		return nil, fmt.Errorf("Missing pattern for the default locale %q",
+			defaultLocale)
+	}
+	m := &LocalizedRoute{
+		DefaultLocale: defaultLocale,
+		paths:         make(map[string]*GorillaPath, len(patterns)),
+	}
+	for locale, pattern := range patterns {
+		path, err := NewGorillaPath(pattern, false, opts...)
+		if err != nil {
+			return nil, err
+		}
+		m.locales = append(m.locales, locale)
+		m.paths[locale] = path
+	}
+	sort.Strings(m.locales)
+	return m, nil
+}
+
+// LocalizedRoute matches a URL path against alternative patterns for the
+// same logical route, one per locale. The locale of the matching pattern is
+// extracted using LocaleKey, and URLs are built for the locale set in the
+// values.
+type LocalizedRoute struct {
+	DefaultLocale string
+	locales       []string
+	paths         map[string]*GorillaPath
+}
+
+// Locales returns the sorted list of locales.
+func (m *LocalizedRoute) Locales() []string {
+	return m.locales
+}
+
+// Locale returns the locale of the pattern matching the request, or false
+// if none matches. If more than one matches, the first one in sorted order
+// is returned.
+func (m *LocalizedRoute) Locale(r *http.Request) (string, bool) {
+	for _, locale := range m.locales {
+		if m.paths[locale].Match(r) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+func (m *LocalizedRoute) Match(r *http.Request) bool {
+	_, ok := m.Locale(r)
+	return ok
+}
+
+func (m *LocalizedRoute) String() string {
+	parts := make([]string, len(m.locales))
+	for k, locale := range m.locales {
+		parts[k] = fmt.Sprintf("%q=%q", locale, m.paths[locale].pattern)
+	}
+	return "LocalizedRoute(" + strings.Join(parts, ", ") + ")"
+}
+
+// Extract returns the variables extracted from the URL path, and the locale
+// of the matching pattern using LocaleKey.
+func (m *LocalizedRoute) Extract(result *Result, r *http.Request) {
+	if locale, ok := m.Locale(r); ok {
+		m.paths[locale].Extract(result, r)
+		result.Values = mergeValues(result.Values,
+			url.Values{LocaleKey: {locale}})
+	}
+}
+
+// Build builds the URL path for the locale set in the values using
+// LocaleKey, or the default locale if it is not set.
+func (m *LocalizedRoute) Build(u *url.URL, values url.Values) error {
+	locale := m.DefaultLocale
+	if v := values[LocaleKey]; len(v) != 0 {
+		locale = v[0]
+		values[LocaleKey] = v[1:]
+	}
+	return m.BuildLocale(u, locale, values)
+}
+
+// BuildLocale builds the URL path for the given locale.
+func (m *LocalizedRoute) BuildLocale(u *url.URL, locale string, values url.Values) error {
+	path, ok := m.paths[locale]
+	if !ok {
+		return fmt.Errorf("Unknown locale %q", locale)
+	}
+	return path.Build(u, values)
+}
@@ -0,0 +1,54 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestLocalizedRoute(t *testing.T) {
+	m, err := NewLocalizedRoute(map[string]string{
+		"en": "/en/products/{id}",
+		"de": "/de/produkte/{id}",
+	}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/de/produkte/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "LocalizedRoute", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	expect := url.Values{"id": {"42"}, LocaleKey: {"de"}}
+	if !equalValues(expect, result.Values) {
+		t.Errorf("expected %v, got %v", expect, result.Values)
+	}
+	tests := []struct {
+		values url.Values
+		path   string
+	}{
+		{url.Values{"id": {"42"}, LocaleKey: {"de"}}, "/de/produkte/42"},
+		{url.Values{"id": {"42"}}, "/en/products/42"},
+	}
+	for _, v := range tests {
+		u := url.URL{}
+		if err := m.Build(&u, v.values); err != nil {
+			t.Fatal(err)
+		}
+		if u.Path != v.path {
+			t.Errorf("expected %q, got %q", v.path, u.Path)
+		}
+	}
+	if err := m.BuildLocale(&url.URL{}, "fr", url.Values{"id": {"1"}}); err == nil {
+		t.Errorf("expected error for unknown locale")
+	}
+	if _, err := NewLocalizedRoute(map[string]string{"en": "/en"}, "fr"); err == nil {
+		t.Errorf("expected error for missing default locale")
+	}
+}
@@ -5,15 +5,36 @@
 package reverse
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 )
 
 // Result stores the results from a match.
+//
+// Besides the handler and variables, it describes what matched, so that
+// middlewares can log or record metrics by route template instead of the
+// request URL; see MatchResult.
 type Result struct {
 	Handler http.Handler
 	Values  url.Values
+	// Route is the name of the matched route, set by Router.
+	Route string
+	// Matcher is what matched: the *Route for Router, and the entry
+	// matcher for RouteList and MatchHandler.
+	Matcher Matcher
+	// Pattern is the path template that matched, like "/users/{id}", set by
+	// the Gorilla path matchers and Mount.
+	Pattern string
+	// HostPattern is the host template that matched, like
+	// "{tenant}.example.com", set by GorillaHost and WildcardHost.
+	HostPattern string
+	// Metadata is the metadata of the matched route or entry, set by Router
+	// and RouteList. It must not be modified.
+	Metadata map[string]interface{}
 }
 
 // Matcher matches a request.
@@ -31,6 +52,18 @@ type Builder interface {
 	Build(*url.URL, url.Values) error
 }
 
+// MatchExtract matches the request and, if it matches, returns the result of
+// extracting its variables. The result is empty if the matcher is not an
+// Extractor.
+func MatchExtract(m Matcher, r *http.Request) (*Result, bool) {
+	if !m.Match(r) {
+		return nil, false
+	}
+	result := &Result{}
+	extractMatcher(m, result, r)
+	return result, true
+}
+
 // Func -----------------------------------------------------------------------
 
 // Func is a function signature for custom matchers.
@@ -40,6 +73,10 @@ func (m Func) Match(r *http.Request) bool {
 	return m(r)
 }
 
+func (m Func) String() string {
+	return "Func"
+}
+
 // Header ---------------------------------------------------------------------
 
 // NewHeader returns a header matcher, converting keys to the canonical form.
@@ -73,6 +110,10 @@ loop:
 	return true
 }
 
+func (m Header) String() string {
+	return "Header(" + formatPairs(m) + ")"
+}
+
 // Host -----------------------------------------------------------------------
 
 // NewHost returns a static URL host matcher.
@@ -87,6 +128,14 @@ func (m Host) Match(r *http.Request) bool {
 	return getHost(r) == string(m)
 }
 
+func (m Host) MatchInfo(info *RequestInfo) bool {
+	return info.Host() == string(m)
+}
+
+func (m Host) String() string {
+	return fmt.Sprintf("Host(%q)", string(m))
+}
+
 // Method ---------------------------------------------------------------------
 
 // NewMethod retuns a request method matcher, converting values to upper-case.
@@ -109,20 +158,54 @@ func (m Method) Match(r *http.Request) bool {
 	return false
 }
 
+func (m Method) String() string {
+	return "Method(" + formatList(m) + ")"
+}
+
 // None -----------------------------------------------------------------------
 
-// NewNone returns a matcher that never matches.
+// NewNone returns a matcher that never matches. The pointer is never nil.
 func NewNone() *None {
-	return nil
+	return &None{}
 }
 
 // None never matches.
-type None bool
+type None struct{}
 
-func (m *None) Match(r *http.Request) bool {
+func (m None) Match(r *http.Request) bool {
 	return false
 }
 
+func (m None) String() string {
+	return "None()"
+}
+
+// Extract does nothing; it is here so None can be used as an Extractor.
+func (m None) Extract(result *Result, r *http.Request) {
+}
+
+// Always ---------------------------------------------------------------------
+
+// NewAlways returns a matcher that always matches.
+func NewAlways() Always {
+	return Always{}
+}
+
+// Always always matches.
+type Always struct{}
+
+func (m Always) Match(r *http.Request) bool {
+	return true
+}
+
+func (m Always) String() string {
+	return "Always()"
+}
+
+// Extract does nothing; it is here so Always can be used as an Extractor.
+func (m Always) Extract(result *Result, r *http.Request) {
+}
+
 // Path -----------------------------------------------------------------------
 
 // NewPath returns a static URL path matcher.
@@ -140,6 +223,10 @@ func (m Path) Match(r *http.Request) bool {
 	return r.URL.Path == string(m)
 }
 
+func (m Path) String() string {
+	return fmt.Sprintf("Path(%q)", string(m))
+}
+
 // PathRedirect ---------------------------------------------------------------
 
 // NewPathRedirect returns a static URL path matcher that redirects if the
@@ -159,9 +246,40 @@ func (m PathRedirect) Match(r *http.Request) bool {
 	return strings.TrimRight(r.URL.Path, "/") == strings.TrimRight(string(m), "/")
 }
 
+func (m PathRedirect) String() string {
+	return fmt.Sprintf("PathRedirect(%q)", string(m))
+}
+
 func (m PathRedirect) Extract(result *Result, r *http.Request) {
 	if result.Handler == nil {
-		result.Handler = redirectPath(string(m), r)
+		result.Handler = redirectPath(string(m), r, false,
+			http.StatusMovedPermanently)
+	}
+}
+
+// NewPathRedirectCode is like NewPathRedirect, but redirects with the given
+// status code: 301 (http.StatusMovedPermanently), 302 (http.StatusFound),
+// 307 (http.StatusTemporaryRedirect) or 308 (http.StatusPermanentRedirect).
+// The last two keep the request method, which matters for POST endpoints.
+func NewPathRedirectCode(path string, code int) PathRedirectCode {
+	return PathRedirectCode{PathRedirect: NewPathRedirect(path), Code: code}
+}
+
+// PathRedirectCode is a PathRedirect using the given redirect status code;
+// a zero Code uses 301.
+type PathRedirectCode struct {
+	PathRedirect
+	Code int
+}
+
+func (m PathRedirectCode) String() string {
+	return fmt.Sprintf("PathRedirectCode(%q, %d)", string(m.PathRedirect), m.Code)
+}
+
+func (m PathRedirectCode) Extract(result *Result, r *http.Request) {
+	if result.Handler == nil {
+		code := redirectCode(m.Code)
+		result.Handler = redirectPath(string(m.PathRedirect), r, false, code)
 	}
 }
 
@@ -182,6 +300,55 @@ func (m PathPrefix) Match(r *http.Request) bool {
 	return strings.HasPrefix(r.URL.Path, string(m))
 }
 
+func (m PathPrefix) String() string {
+	return fmt.Sprintf("PathPrefix(%q)", string(m))
+}
+
+// PathFold -------------------------------------------------------------------
+
+// NewPathFold returns a case-insensitive static URL path matcher.
+func NewPathFold(path string) PathFold {
+	return PathFold(NewPath(path))
+}
+
+// PathFold matches a static URL path, ignoring case. Built paths use the
+// case of the matcher, so it is the canonical one.
+type PathFold string
+
+func (m PathFold) Match(r *http.Request) bool {
+	return strings.EqualFold(r.URL.Path, string(m))
+}
+
+func (m PathFold) String() string {
+	return fmt.Sprintf("PathFold(%q)", string(m))
+}
+
+// Build writes the path to the given URL.
+func (m PathFold) Build(u *url.URL, values url.Values) error {
+	u.Path = string(m)
+	return nil
+}
+
+// PathPrefixFold -------------------------------------------------------------
+
+// NewPathPrefixFold returns a case-insensitive static URL path prefix
+// matcher.
+func NewPathPrefixFold(prefix string) PathPrefixFold {
+	return PathPrefixFold(NewPathPrefix(prefix))
+}
+
+// PathPrefixFold matches a static URL path prefix, ignoring case.
+type PathPrefixFold string
+
+func (m PathPrefixFold) Match(r *http.Request) bool {
+	return len(r.URL.Path) >= len(m) &&
+		strings.EqualFold(r.URL.Path[:len(m)], string(m))
+}
+
+func (m PathPrefixFold) String() string {
+	return fmt.Sprintf("PathPrefixFold(%q)", string(m))
+}
+
 // Query ----------------------------------------------------------------------
 
 // NewQuery returns a URL query matcher.
@@ -194,7 +361,15 @@ func NewQuery(m map[string]string) Query {
 type Query map[string]string
 
 func (m Query) Match(r *http.Request) bool {
-	src := r.URL.Query()
+	return m.match(r.URL.Query())
+}
+
+func (m Query) MatchInfo(info *RequestInfo) bool {
+	return m.match(info.Query())
+}
+
+// match returns whether the parsed query matches.
+func (m Query) match(src url.Values) bool {
 loop:
 	for k, v := range m {
 		if values, ok := src[k]; !ok {
@@ -211,6 +386,10 @@ loop:
 	return true
 }
 
+func (m Query) String() string {
+	return "Query(" + formatPairs(m) + ")"
+}
+
 // Scheme ---------------------------------------------------------------------
 
 // NewScheme retuns a URL scheme matcher, converting values to lower-case.
@@ -233,21 +412,55 @@ func (m Scheme) Match(r *http.Request) bool {
 	return false
 }
 
+func (m Scheme) String() string {
+	return "Scheme(" + formatList(m) + ")"
+}
+
+// Build writes the first scheme to the given URL if it has a host, so that
+// path-only URLs stay relative. It must come after the host builder.
+func (m Scheme) Build(u *url.URL, values url.Values) error {
+	if len(m) != 0 && u.Host != "" {
+		u.Scheme = m[0]
+	}
+	return nil
+}
+
 // Helpers --------------------------------------------------------------------
 
-// getHost tries its best to return the request host.
+// getHost returns the request host, without the port.
 func getHost(r *http.Request) string {
-	if r.URL.IsAbs() {
-		host := r.Host
-		// Slice off any port information.
-		if i := strings.Index(host, ":"); i != -1 {
-			host = host[:i]
-		}
-		return host
+	host, _ := splitHostPort(getHostPort(r))
+	return host
+}
+
+// getHostPort returns the request host, including the port if any.
+func getHostPort(r *http.Request) string {
+	if r.Host != "" {
+		return r.Host
 	}
 	return r.URL.Host
 }
 
+// getPort returns the request port, or the default one for the scheme.
+func getPort(r *http.Request) string {
+	if _, port := splitHostPort(getHostPort(r)); port != "" {
+		return port
+	}
+	if r.TLS != nil || r.URL.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// splitHostPort splits a host into host and port, removing the brackets from
+// IPv6 literals. The port is empty if there is none.
+func splitHostPort(hostport string) (host, port string) {
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		return h, p
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]"), ""
+}
+
 // mergeValues returns the result of merging two url.Values.
 func mergeValues(u1, u2 url.Values) url.Values {
 	if u1 == nil {
@@ -262,19 +475,86 @@ func mergeValues(u1, u2 url.Values) url.Values {
 	return u1
 }
 
-// redirectPath returns a handler that redirects if the path trailing slash
-// differs from the request URL path.
-func redirectPath(path string, r *http.Request) http.Handler {
-	t1 := strings.HasSuffix(path, "/")
-	t2 := strings.HasSuffix(r.URL.Path, "/")
-	if t1 != t2 {
-		u, _ := url.Parse(r.URL.String())
-		if t1 {
-			u.Path += "/"
-		} else {
-			u.Path = u.Path[:len(u.Path)-1]
+// cloneValues returns a deep copy of url.Values.
+func cloneValues(values url.Values) url.Values {
+	rv := make(url.Values, len(values))
+	for k, v := range values {
+		rv[k] = append([]string(nil), v...)
+	}
+	return rv
+}
+
+// sameValues returns whether two url.Values have the same keys and values.
+func sameValues(u1, u2 url.Values) bool {
+	if len(u1) != len(u2) {
+		return false
+	}
+	for k, v := range u1 {
+		w, ok := u2[k]
+		if !ok || len(v) != len(w) {
+			return false
+		}
+		for i := range v {
+			if v[i] != w[i] {
+				return false
+			}
 		}
-		return http.RedirectHandler(u.String(), 301)
 	}
-	return nil
+	return true
+}
+
+// formatList returns a comma-separated list of quoted strings.
+func formatList(values []string) string {
+	parts := make([]string, len(values))
+	for k, v := range values {
+		parts[k] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatPairs returns a comma-separated list of quoted key/value pairs,
+// sorted by key.
+func formatPairs(m map[string]string) string {
+	keys := sortedKeys(m)
+	parts := make([]string, len(keys))
+	for k, v := range keys {
+		parts[k] = fmt.Sprintf("%q=%q", v, m[v])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setQuery sets a query parameter in the given URL, replacing its values.
+func setQuery(u *url.URL, key, value string) {
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+}
+
+// redirectCode returns the given redirect status code, or 301
+// (http.StatusMovedPermanently) if it is zero.
+func redirectCode(code int) int {
+	if code == 0 {
+		return http.StatusMovedPermanently
+	}
+	return code
+}
+
+// redirectPath returns a handler that redirects if the path trailing slash
+// differs from the request URL path.
+//
+// The redirect target is rebuilt from the request URL components, so an
+// encoded path (RawPath) is preserved. The query is kept unless stripQuery
+// is true.
+func redirectPath(path string, r *http.Request, stripQuery bool, code int) http.Handler {
+	return patternSlashPolicy(path).redirect(r, stripQuery, code)
 }
@@ -5,8 +5,12 @@
 package reverse
 
 import (
+	"bufio"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -51,6 +55,9 @@ func TestHost(t *testing.T) {
 	tests := []test{
 		{"domain.com", "http://domain.com", true},
 		{"domain.com", "http://other.com", false},
+		{"domain.com", "http://domain.com:8080", true},
+		{"::1", "http://[::1]:8080", true},
+		{"::1", "http://[::1]", true},
 	}
 	for _, v := range tests {
 		r, err := http.NewRequest("GET", v.rURL, nil)
@@ -59,6 +66,215 @@ func TestHost(t *testing.T) {
 		}
 		testMatcher(t, name, NewHost(v.host), r, v.expect)
 	}
+	// Server requests have no host in the URL.
+	r, err := http.ReadRequest(bufio.NewReader(strings.NewReader(
+		"GET /a HTTP/1.1\r\nHost: domain.com:8080\r\n\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, name, NewHost("domain.com"), r, true)
+}
+
+func TestHostPort(t *testing.T) {
+	const name = "HostPort"
+	tests := []struct {
+		pattern string
+		rURL    string
+		expect  bool
+		values  url.Values
+		build   string
+	}{
+		{"{sub}.domain.com:8080", "http://www.domain.com:8080/", true, url.Values{"sub": {"www"}, PortKey: {"8080"}}, "www.domain.com:8080"},
+		{"{sub}.domain.com:8080", "http://www.domain.com/", false, nil, ""},
+		{"domain.com:80", "http://domain.com/", true, url.Values{PortKey: {"80"}}, "domain.com:80"},
+		{"domain.com:443", "https://domain.com/", true, url.Values{PortKey: {"443"}}, "domain.com:443"},
+		{"domain.com:{port:[0-9]+}", "http://domain.com:9000/", true, url.Values{PortKey: {"9000"}}, "domain.com:9000"},
+		{"[::1]:{p}", "http://[::1]:3000/", true, url.Values{"p": {"3000"}, PortKey: {"3000"}}, "[::1]:3000"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matcher := MustNewHostPort(v.pattern)
+		testMatcher(t, name, matcher, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+		u := &url.URL{}
+		if err := matcher.Build(u, result.Values); err != nil {
+			t.Errorf("%s: error building URL: %v", name, err)
+		} else if u.Host != v.build {
+			t.Errorf("%s: expected host %q, got %q", name, v.build, u.Host)
+		}
+	}
+}
+
+func TestGorillaHost(t *testing.T) {
+	const name = "GorillaHost"
+	tests := []struct {
+		pattern string
+		rURL    string
+		expect  bool
+		values  url.Values
+		build   string
+	}{
+		{"{sub}.domain.com", "http://www.domain.com:8080/", true, url.Values{"sub": {"www"}}, "http://www.domain.com"},
+		{"{sub}.domain.com:{port:[0-9]+}", "http://www.domain.com:8080/", true, url.Values{"sub": {"www"}, "port": {"8080"}}, "http://www.domain.com:8080"},
+		{"{sub}.domain.com:8080", "http://www.domain.com/", false, nil, ""},
+		{"https://{sub}.domain.com", "https://www.domain.com/", true, url.Values{"sub": {"www"}}, "https://www.domain.com"},
+		{"https://{sub}.domain.com", "http://www.domain.com/", false, nil, ""},
+		{"https://domain.com:443", "https://domain.com/", true, nil, "https://domain.com:443"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matcher := MustNewGorillaHost(v.pattern)
+		testMatcher(t, name, matcher, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+		u := &url.URL{}
+		if err := matcher.Build(u, result.Values); err != nil {
+			t.Errorf("%s: error building URL: %v", name, err)
+		} else if u.String() != v.build {
+			t.Errorf("%s: expected %q, got %q", name, v.build, u.String())
+		}
+	}
+}
+
+func TestNoneAlways(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://domain.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "None", NewNone(), r, false)
+	testMatcher(t, "Always", NewAlways(), r, true)
+	var m Matcher = NewNone()
+	if m == nil {
+		t.Errorf("None: expected a non-nil matcher")
+	}
+	var _ Extractor = NewNone()
+	var _ Extractor = NewAlways()
+	if !Equal(NewNone(), None{}) {
+		t.Errorf("None: expected the pointer and value forms to be equal")
+	}
+}
+
+func TestMatchExtract(t *testing.T) {
+	m := NewAll([]Matcher{NewMethod([]string{"GET"}), MustNewGorillaPath("/users/{id}", false)})
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := MatchExtract(m, r)
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if expect := (url.Values{"id": {"42"}}); !equalValues(result.Values, expect) {
+		t.Errorf("expected values %v, got %v", expect, result.Values)
+	}
+	if result, ok := MatchExtract(NewMethod([]string{"GET"}), r); !ok || result.Values != nil {
+		t.Errorf("expected an empty result, got %v, %v", result, ok)
+	}
+	r.Method = "POST"
+	if result, ok := MatchExtract(m, r); ok || result != nil {
+		t.Errorf("expected no match, got %v", result)
+	}
+}
+
+func TestAll(t *testing.T) {
+	m := NewAll([]Matcher{
+		MustNewGorillaHost("{sub}.domain.com"),
+		NewMethod([]string{"GET"}),
+		MustNewGorillaPath("/users/{id}", false),
+	})
+	var _ Extractor = m
+	var _ Builder = m
+	r, err := http.NewRequest("GET", "http://www.domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &Result{}
+	m.Extract(result, r)
+	expect := url.Values{"sub": {"www"}, "id": {"42"}}
+	if !equalValues(result.Values, expect) {
+		t.Errorf("expected values %v, got %v", expect, result.Values)
+	}
+	u := &url.URL{}
+	if err := m.Build(u, url.Values{"sub": {"api"}, "id": {"7"}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "http://api.domain.com/users/7" {
+		t.Errorf("expected %q, got %q", "http://api.domain.com/users/7", s)
+	}
+	if err := m.Build(&url.URL{}, url.Values{"id": {"7"}}); err == nil {
+		t.Errorf("expected an error for a missing host variable")
+	}
+}
+
+func TestOne(t *testing.T) {
+	m := NewOne([]Matcher{
+		MustNewGorillaPath("/users/{id:[0-9]+}", false),
+		MustNewGorillaPath("/users/{name}", false),
+	})
+	tests := []struct {
+		path   string
+		index  int
+		values url.Values
+	}{
+		{"/users/42", 0, url.Values{"id": {"42"}}},
+		{"/users/joe", 1, url.Values{"name": {"joe"}}},
+		{"/posts/42", -1, nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://localhost"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i := m.MatchIndex(r); i != v.index {
+			t.Errorf("%s: expected index %d, got %d", v.path, v.index, i)
+		}
+		result := &Result{}
+		m.Extract(result, r)
+		if !equalValues(result.Values, v.values) {
+			t.Errorf("%s: expected values %v, got %v", v.path, v.values, result.Values)
+		}
+	}
+}
+
+func TestNot(t *testing.T) {
+	m := NewAll([]Matcher{NewPathPrefix("/api"), NewNot(NewPathPrefix("/api/internal"))})
+	tests := []struct {
+		rURL   string
+		expect bool
+	}{
+		{"http://domain.com/api/users", true},
+		{"http://domain.com/api/internal/users", false},
+		{"http://domain.com/other", false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, "Not", m, r, v.expect)
+	}
+	if s := m.String(); s != `All(PathPrefix("/api"), Not(PathPrefix("/api/internal")))` {
+		t.Errorf("unexpected string %s", s)
+	}
 }
 
 func TestMethod(t *testing.T) {
@@ -166,6 +382,204 @@ func TestRegexpPath(t *testing.T) {
 	}
 }
 
+func TestGorillaOptional(t *testing.T) {
+	const name = "GorillaPath"
+	matcher := MustNewGorillaPath("/articles/{category}/{id:[0-9]+}?", false)
+	tests := []struct {
+		path   string
+		expect bool
+		values url.Values
+	}{
+		{"/articles/go", true, url.Values{"category": {"go"}}},
+		{"/articles/go/42", true, url.Values{"category": {"go"}, "id": {"42"}}},
+		{"/articles/go/", false, nil},
+		{"/articles/go/a", false, nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+		u := &url.URL{}
+		if err := matcher.Build(u, result.Values); err != nil {
+			t.Errorf("%s: error building URL: %v", name, err)
+		} else if u.Path != v.path {
+			t.Errorf("%s: expected path %q, got %q", name, v.path, u.Path)
+		}
+	}
+}
+
+func TestGorillaCatchAll(t *testing.T) {
+	const name = "GorillaPath"
+	for _, pattern := range []string{"/static/{path:*}", "/static/{path...}"} {
+		matcher := MustNewGorillaPath(pattern, false)
+		for _, path := range []string{"/static/", "/static/a", "/static/a/b/c.css"} {
+			r, err := http.NewRequest("GET", "http://domain.com"+path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testMatcher(t, name, matcher, r, true)
+			result := Result{}
+			matcher.Extract(&result, r)
+			u := &url.URL{}
+			if err := matcher.Build(u, result.Values); err != nil {
+				t.Errorf("%s: error building URL: %v", name, err)
+			} else if u.Path != path {
+				t.Errorf("%s: expected path %q, got %q", name, path, u.Path)
+			}
+		}
+		r, _ := http.NewRequest("GET", "http://domain.com/other/a", nil)
+		testMatcher(t, name, matcher, r, false)
+	}
+}
+
+func TestGorillaVars(t *testing.T) {
+	path := MustNewGorillaPath("/{category}/{id:int}/{rest:*}", false)
+	host := MustNewGorillaHost("https://{sub}.domain.com:{port:[0-9]+}")
+	prefix := MustNewGorillaPathPrefix("/{lang:[a-z]{2}}", WithDefaultPattern("[a-z]+"))
+	tests := []struct {
+		names    []string
+		expect   []string
+		vars     []string
+		patterns func(string) string
+	}{
+		{path.VarNames(), []string{"category", "id", "rest"},
+			[]string{"[^/]+", `-?[0-9]+`, ".*"}, path.VarPattern},
+		{host.VarNames(), []string{"sub", "port"},
+			[]string{"[^.]+", "[0-9]+"}, host.VarPattern},
+		{prefix.VarNames(), []string{"lang"},
+			[]string{"[a-z]{2}"}, prefix.VarPattern},
+	}
+	for _, v := range tests {
+		if !stringSliceEqual(v.names, v.expect) {
+			t.Errorf("expected names %v, got %v", v.expect, v.names)
+			continue
+		}
+		for k, name := range v.names {
+			if p := v.patterns(name); p != v.vars[k] {
+				t.Errorf("%s: expected pattern %q, got %q", name, v.vars[k], p)
+			}
+		}
+	}
+	if p := path.VarPattern("unknown"); p != "" {
+		t.Errorf("expected an empty pattern, got %q", p)
+	}
+}
+
+func TestGorillaDefaults(t *testing.T) {
+	const name = "GorillaPath"
+	matcher := MustNewGorillaPath("/articles/{page=1:[0-9]+}", false)
+	tests := []struct {
+		path   string
+		expect bool
+		page   string
+	}{
+		{"/articles", true, "1"},
+		{"/articles/3", true, "3"},
+		{"/articles/a", false, ""},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		matcher.Extract(&result, r)
+		if p := result.Values.Get("page"); p != v.page {
+			t.Errorf("%s: expected page %q, got %q", v.path, v.page, p)
+		}
+	}
+	u := &url.URL{}
+	if err := matcher.Build(u, url.Values{}); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/articles/1" {
+		t.Errorf("expected %q, got %q", "/articles/1", u.Path)
+	}
+	if _, err := NewGorillaPath("/{page=}", false); err == nil {
+		t.Errorf("expected error for missing default value")
+	}
+	// "=" in a pattern is a literal, not a default, so defaults go after
+	// the name instead of after the pattern.
+	kv := MustNewGorillaPath("/kv/{pair:[a-z]+=[0-9]+}", false)
+	for path, expect := range map[string]bool{"/kv/a=1": true, "/kv/a": false} {
+		r, err := http.NewRequest("GET", "http://domain.com"+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, kv, r, expect)
+	}
+	segments, err := ParseGorillaTemplate("/articles/{page:[0-9]+=1}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg := segments[1]; seg.Pattern != "[0-9]+=1" || seg.Default != "" {
+		t.Errorf("expected pattern %q without default, got %+v", "[0-9]+=1", seg)
+	}
+}
+
+func TestParseGorillaTemplate(t *testing.T) {
+	segments, err := ParseGorillaTemplate("/articles/{category}/{id:[0-9]+}?/{rest...}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Segment{
+		{Kind: LiteralSegment, Literal: "/articles/"},
+		{Kind: VariableSegment, Name: "category"},
+		{Kind: LiteralSegment, Literal: "/"},
+		{Kind: VariableSegment, Name: "id", Pattern: "[0-9]+", Optional: true},
+		{Kind: LiteralSegment, Literal: "/"},
+		{Kind: VariableSegment, Name: "rest", Pattern: "*"},
+	}
+	if fmt.Sprint(segments) != fmt.Sprint(expect) {
+		t.Errorf("expected %v, got %v", expect, segments)
+	}
+	for _, tpl := range []string{"/{id", "/{}", "/{id:}"} {
+		if _, err := ParseGorillaTemplate(tpl); err == nil {
+			t.Errorf("%q: expected error", tpl)
+		}
+	}
+}
+
+func TestPathFold(t *testing.T) {
+	tests := []struct {
+		matcher Matcher
+		path    string
+		expect  bool
+	}{
+		{NewPathFold("/Foo"), "/foo", true},
+		{NewPathFold("/Foo"), "/FOO", true},
+		{NewPathFold("/Foo"), "/foo/", false},
+		{NewPathPrefixFold("/Static/"), "/static/a.css", true},
+		{NewPathPrefixFold("/Static/"), "/STATIC/", true},
+		{NewPathPrefixFold("/Static/"), "/stat", false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, fmt.Sprint(v.matcher), v.matcher, r, v.expect)
+	}
+	u := &url.URL{}
+	if err := NewPathFold("Foo").Build(u, nil); err != nil || u.Path != "/Foo" {
+		t.Errorf("expected path %q, got %q (%v)", "/Foo", u.Path, err)
+	}
+}
+
 func TestScheme(t *testing.T) {
 	const name = "Scheme"
 	type test struct {
@@ -186,3 +600,215 @@ func TestScheme(t *testing.T) {
 		testMatcher(t, name, NewScheme(v.schemes), r, v.expect)
 	}
 }
+
+func TestRedirectPath(t *testing.T) {
+	type test struct {
+		path       string
+		rURL       string
+		stripQuery bool
+		location   string
+	}
+	tests := []test{
+		{"/foo/", "http://domain.com/foo?a=b", false, "http://domain.com/foo/?a=b"},
+		{"/foo/", "http://domain.com/foo?a=b", true, "http://domain.com/foo/"},
+		{"/foo", "http://domain.com/foo/", false, "http://domain.com/foo"},
+		{"/a%2Fb/", "http://domain.com/a%2Fb", false, "http://domain.com/a%2Fb/"},
+		{"/foo/", "http://domain.com/foo/", false, ""},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := redirectPath(v.path, r, v.stripQuery, http.StatusMovedPermanently)
+		if v.location == "" {
+			if h != nil {
+				t.Errorf("%q: expected no redirect", v.rURL)
+			}
+			continue
+		}
+		if h == nil {
+			t.Errorf("%q: expected redirect to %q", v.rURL, v.location)
+			continue
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if loc := w.Header().Get("Location"); loc != v.location {
+			t.Errorf("%q: expected redirect to %q, got %q", v.rURL, v.location, loc)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	gorillaPath, err := NewGorillaPath("/{id:[0-9]+}", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regexpHost, err := NewRegexpHost(`^(?P<sub>[a-z]+)\.domain\.com$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		matcher Matcher
+		expect  string
+	}{
+		{NewPath("foo"), `Path("/foo")`},
+		{NewHost("domain.com"), `Host("domain.com")`},
+		{NewMethod([]string{"get", "post"}), `Method("GET", "POST")`},
+		{NewHeader(map[string]string{"x-b": "", "accept": "text/html"}), `Header("Accept"="text/html", "X-B"="")`},
+		{NewQuery(map[string]string{"q": "1"}), `Query("q"="1")`},
+		{NewNone(), `None()`},
+		{gorillaPath, `GorillaPath("/{id:[0-9]+}")`},
+		{regexpHost, `RegexpHost("^(?P<sub>[a-z]+)\\.domain\\.com$")`},
+		{NewAll([]Matcher{NewScheme([]string{"HTTPS"}), NewOne([]Matcher{NewPathPrefix("/a"), NewAlways()})}), `All(Scheme("https"), One(PathPrefix("/a"), Always()))`},
+	}
+	for _, v := range tests {
+		if s := fmt.Sprint(v.matcher); s != v.expect {
+			t.Errorf("expected %s, got %s", v.expect, s)
+		}
+	}
+}
+
+func TestRegexpQuery(t *testing.T) {
+	const name = "RegexpQuery"
+	type test struct {
+		key     string
+		pattern string
+		rURL    string
+		expect  bool
+		values  url.Values
+	}
+	tests := []test{
+		{"page", `^(?P<page>\d+)$`, "http://domain.com/?page=2&q=a", true, url.Values{"page": {"2"}}},
+		{"page", `^(?P<page>\d+)$`, "http://domain.com/?page=a&page=3", true, url.Values{"page": {"3"}}},
+		{"page", `^(?P<page>\d+)$`, "http://domain.com/?page=a", false, nil},
+		{"page", `^(?P<page>\d+)$`, "http://domain.com/", false, nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matcher, err := NewRegexpQuery(v.key, v.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+		if v.expect {
+			u := url.URL{RawQuery: "q=a"}
+			if err := matcher.Build(&u, result.Values); err != nil {
+				t.Errorf("%s: error building URL: %v", name, err)
+			} else if q := u.Query(); q.Get("q") != "a" || q.Get(v.key) != v.values.Get(v.key) {
+				t.Errorf("%s: unexpected query %q", name, u.RawQuery)
+			}
+		}
+	}
+}
+
+func TestRegexpHeader(t *testing.T) {
+	const name = "RegexpHeader"
+	pattern := `^application/vnd\.api\.v(?P<version>\d+)\+json$`
+	tests := []struct {
+		accept []string
+		expect bool
+		values url.Values
+	}{
+		{[]string{"application/vnd.api.v2+json"}, true, url.Values{"version": {"2"}}},
+		{[]string{"text/html", "application/vnd.api.v3+json"}, true, url.Values{"version": {"3"}}},
+		{[]string{"application/json"}, false, nil},
+		{nil, false, nil},
+	}
+	matcher := MustNewRegexpHeader("accept", pattern)
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header["Accept"] = v.accept
+		testMatcher(t, name, matcher, r, v.expect)
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+	}
+}
+
+func TestRegexpAttr(t *testing.T) {
+	const name = "RegexpAttr"
+	tests := []struct {
+		referer string
+		expect  bool
+		values  url.Values
+	}{
+		{"https://example.com/page", true, url.Values{"site": {"example.com"}}},
+		{"http://example.com/page", false, nil},
+		{"", false, nil},
+	}
+	matcher := MustNewRegexpAttr("referer", (*http.Request).Referer,
+		`^https://(?P<site>[^/]+)/`)
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Referer", v.referer)
+		testMatcher(t, name, matcher, r, v.expect)
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+	}
+	if s := matcher.String(); s != `RegexpAttr("referer", "^https://(?P<site>[^/]+)/")` {
+		t.Errorf("%s: unexpected string %q", name, s)
+	}
+}
+
+func TestPathRedirectCode(t *testing.T) {
+	tests := []struct {
+		m        Matcher
+		target   string
+		code     int
+		location string
+	}{
+		{NewPathRedirect("/form/"), "http://domain.com/form", http.StatusMovedPermanently, "http://domain.com/form/"},
+		{NewPathRedirectCode("/form/", http.StatusPermanentRedirect), "http://domain.com/form",
+			http.StatusPermanentRedirect, "http://domain.com/form/"},
+		{NewPathRedirectCode("/form", http.StatusTemporaryRedirect), "http://domain.com/form/",
+			http.StatusTemporaryRedirect, "http://domain.com/form"},
+		{PathRedirectCode{PathRedirect: "/form"}, "http://domain.com/form/",
+			http.StatusMovedPermanently, "http://domain.com/form"},
+		{NewPathRedirectCode("/form", http.StatusFound), "http://domain.com/form", 0, ""},
+		{NewSlashPath("/form", SlashRedirectToSlash), "http://domain.com/form",
+			http.StatusMovedPermanently, "http://domain.com/form/"},
+		{SlashPath{Path: "/form", Policy: SlashRedirectToSlash, Code: http.StatusPermanentRedirect},
+			"http://domain.com/form", http.StatusPermanentRedirect, "http://domain.com/form/"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("POST", v.target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, fmt.Sprint(v.m), v.m, r, true)
+		result := &Result{}
+		v.m.(Extractor).Extract(result, r)
+		if v.code == 0 {
+			if result.Handler != nil {
+				t.Errorf("%v: unexpected redirect", v.m)
+			}
+			continue
+		}
+		w := httptest.NewRecorder()
+		result.Handler.ServeHTTP(w, r)
+		if w.Code != v.code || w.Header().Get("Location") != v.location {
+			t.Errorf("%v: expected %d %q, got %d %q", v.m, v.code, v.location,
+				w.Code, w.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeKey is the variable name used by ContentType for the
+	// request media type.
+	ContentTypeKey = "contentType"
+	// AcceptKey is the variable name used by Accept for the negotiated media
+	// type.
+	AcceptKey = "accept"
+)
+
+// ContentType ----------------------------------------------------------------
+
+// NewContentType returns a Content-Type matcher for the given media ranges,
+// converting them to lower-case.
+func NewContentType(m []string) ContentType {
+	for k, v := range m {
+		m[k] = strings.ToLower(v)
+	}
+	return ContentType(m)
+}
+
+// ContentType matches the media type of the request Content-Type header.
+// One of the media ranges must match; ranges can use wildcards, like
+// "application/*" or "*/*", and parameters, like "text/plain; charset=utf-8",
+// which must be present in the header.
+type ContentType []string
+
+func (m ContentType) Match(r *http.Request) bool {
+	_, ok := m.mediaType(r)
+	return ok
+}
+
+func (m ContentType) String() string {
+	return "ContentType(" + formatList(m) + ")"
+}
+
+// Extract returns the request media type, without parameters, using
+// ContentTypeKey.
+func (m ContentType) Extract(result *Result, r *http.Request) {
+	if typ, ok := m.mediaType(r); ok {
+		result.Values = mergeValues(result.Values,
+			url.Values{ContentTypeKey: {typ}})
+	}
+}
+
+// mediaType returns the request media type if one of the ranges matches.
+func (m ContentType) mediaType(r *http.Request) (string, bool) {
+	typ, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	for _, v := range m {
+		if rng, ok := parseMediaRange(v); ok && rng.match(typ, params) {
+			return typ, true
+		}
+	}
+	return "", false
+}
+
+// Accept ---------------------------------------------------------------------
+
+// NewAccept returns an Accept matcher for the given offered media types,
+// converting them to lower-case.
+func NewAccept(m []string) Accept {
+	for k, v := range m {
+		m[k] = strings.ToLower(v)
+	}
+	return Accept(m)
+}
+
+// Accept matches if one of the offered media types is acceptable according
+// to the request Accept header, which can use wildcards and q-values. A
+// request without the header accepts any media type.
+type Accept []string
+
+func (m Accept) Match(r *http.Request) bool {
+	_, ok := m.Negotiate(r)
+	return ok
+}
+
+func (m Accept) String() string {
+	return "Accept(" + formatList(m) + ")"
+}
+
+// Extract returns the negotiated media type using AcceptKey.
+func (m Accept) Extract(result *Result, r *http.Request) {
+	if typ, ok := m.Negotiate(r); ok {
+		result.Values = mergeValues(result.Values, url.Values{AcceptKey: {typ}})
+	}
+}
+
+// Negotiate returns the offered media type with the highest q-value for the
+// request. Ties are resolved in favor of the first offered type.
+func (m Accept) Negotiate(r *http.Request) (string, bool) {
+	header := strings.Join(r.Header["Accept"], ",")
+	if strings.TrimSpace(header) == "" {
+		if len(m) == 0 {
+			return "", false
+		}
+		return m[0], true
+	}
+	var ranges []mediaRange
+	for _, v := range strings.Split(header, ",") {
+		if rng, ok := parseMediaRange(v); ok {
+			ranges = append(ranges, rng)
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range m {
+		typ, params, err := mime.ParseMediaType(offer)
+		if err != nil {
+			continue
+		}
+		// The most specific matching range sets the q-value.
+		q, specificity := 0.0, -1
+		for _, rng := range ranges {
+			if s := rng.specificity(); s > specificity && rng.match(typ, params) {
+				q, specificity = rng.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// Helpers --------------------------------------------------------------------
+
+// mediaRange is a parsed media range, like "text/*; q=0.5".
+type mediaRange struct {
+	typ     string
+	subtype string
+	params  map[string]string
+	q       float64
+}
+
+// parseMediaRange parses a media range. The q parameter, if any, is removed
+// from the parameters.
+func parseMediaRange(s string) (mediaRange, bool) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		// Some clients send a lone "*" meaning "*/*".
+		s = "*/*"
+	}
+	typ, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return mediaRange{}, false
+	}
+	i := strings.Index(typ, "/")
+	if i == -1 {
+		return mediaRange{}, false
+	}
+	rng := mediaRange{typ: typ[:i], subtype: typ[i+1:], params: params, q: 1}
+	if v, ok := params["q"]; ok {
+		q, err := strconv.ParseFloat(v, 64)
+		if err != nil || q < 0 || q > 1 {
+			return mediaRange{}, false
+		}
+		rng.q = q
+		delete(params, "q")
+	}
+	return rng, true
+}
+
+// match returns whether the range matches a media type and its parameters.
+func (rng mediaRange) match(typ string, params map[string]string) bool {
+	i := strings.Index(typ, "/")
+	if i == -1 {
+		return false
+	}
+	if rng.typ != "*" && rng.typ != typ[:i] {
+		return false
+	}
+	if rng.subtype != "*" && rng.subtype != typ[i+1:] {
+		return false
+	}
+	for k, v := range rng.params {
+		if params[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity returns a number that is higher for more specific ranges.
+func (rng mediaRange) specificity() int {
+	s := len(rng.params)
+	if rng.typ != "*" {
+		s += 1000
+	}
+	if rng.subtype != "*" {
+		s += 1000
+	}
+	return s
+}
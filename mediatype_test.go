@@ -0,0 +1,87 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestContentType(t *testing.T) {
+	const name = "ContentType"
+	tests := []struct {
+		types  []string
+		header string
+		expect bool
+		values url.Values
+	}{
+		{[]string{"application/json"}, "application/json", true, url.Values{ContentTypeKey: {"application/json"}}},
+		{[]string{"application/json"}, "Application/JSON; charset=utf-8", true, url.Values{ContentTypeKey: {"application/json"}}},
+		{[]string{"application/*"}, "application/xml", true, url.Values{ContentTypeKey: {"application/xml"}}},
+		{[]string{"*/*"}, "text/plain", true, url.Values{ContentTypeKey: {"text/plain"}}},
+		{[]string{"text/plain; charset=utf-8"}, "text/plain; charset=utf-8", true, url.Values{ContentTypeKey: {"text/plain"}}},
+		{[]string{"text/plain; charset=utf-8"}, "text/plain", false, nil},
+		{[]string{"application/json"}, "text/html", false, nil},
+		{[]string{"application/json"}, "", false, nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("POST", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.header != "" {
+			r.Header.Set("Content-Type", v.header)
+		}
+		matcher := NewContentType(v.types)
+		testMatcher(t, name, matcher, r, v.expect)
+		result := Result{}
+		matcher.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+	}
+}
+
+func TestAccept(t *testing.T) {
+	const name = "Accept"
+	offers := []string{"application/json", "text/html"}
+	tests := []struct {
+		header string
+		expect bool
+		typ    string
+	}{
+		{"", true, "application/json"},
+		{"text/html", true, "text/html"},
+		{"text/*", true, "text/html"},
+		{"*/*", true, "application/json"},
+		{"*", true, "application/json"},
+		{"application/json;q=0.5, text/html", true, "text/html"},
+		{"text/html;q=0.9, application/json", true, "application/json"},
+		{"*/*;q=0.1, text/html;q=0", true, "application/json"},
+		{"text/*, text/html;q=0", false, ""},
+		{"image/png", false, ""},
+		{"invalid, text/html", true, "text/html"},
+	}
+	matcher := NewAccept(offers)
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.header != "" {
+			r.Header.Set("Accept", v.header)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+		if typ, _ := matcher.Negotiate(r); typ != v.typ {
+			t.Errorf("%s: %q: expected %q, got %q", name, v.header, v.typ, typ)
+		}
+		result := Result{}
+		matcher.Extract(&result, r)
+		if result.Values.Get(AcceptKey) != v.typ {
+			t.Errorf("%s: %q: unexpected values %v", name, v.header, result.Values)
+		}
+	}
+}
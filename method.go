@@ -0,0 +1,174 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SmartMethod ----------------------------------------------------------------
+
+// NewSmartMethod returns a request method matcher with automatic HEAD and
+// OPTIONS handling, converting values to upper-case.
+func NewSmartMethod(m []string) SmartMethod {
+	return SmartMethod(NewMethod(m))
+}
+
+// SmartMethod matches the request method like Method, and also:
+//
+//   - HEAD requests, if GET is allowed;
+//   - OPTIONS requests, which are answered with the allowed methods in the
+//     Allow header, unless OPTIONS is listed explicitly.
+type SmartMethod []string
+
+func (m SmartMethod) Match(r *http.Request) bool {
+	if r.Method == http.MethodOptions {
+		return true
+	}
+	for _, v := range m.Allowed() {
+		if v == r.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func (m SmartMethod) String() string {
+	return "SmartMethod(" + formatList(m) + ")"
+}
+
+// Extract sets a handler for OPTIONS requests that replies with the Allow
+// header, unless OPTIONS is listed explicitly.
+func (m SmartMethod) Extract(result *Result, r *http.Request) {
+	if r.Method != http.MethodOptions || Method(m).Match(r) {
+		return
+	}
+	allow := strings.Join(m.Allowed(), ", ")
+	result.Handler = http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		})
+}
+
+// Allowed returns the allowed methods: the listed ones, HEAD if GET is
+// listed, and OPTIONS.
+func (m SmartMethod) Allowed() []string {
+	allowed := append([]string(nil), m...)
+	var get, head, options bool
+	for _, v := range m {
+		switch v {
+		case http.MethodGet:
+			get = true
+		case http.MethodHead:
+			head = true
+		case http.MethodOptions:
+			options = true
+		}
+	}
+	if get && !head {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !options {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return allowed
+}
+
+// AllowedMethods -------------------------------------------------------------
+
+// AllowedMethods returns the methods allowed by the matcher for a request
+// that would match if it had one of those methods. It returns false if the
+// request doesn't match for other reasons, or if the matcher doesn't check
+// the method.
+//
+// It tells "the route matched but not the method" from "no match", so that
+// callers can reply with 405 (http.StatusMethodNotAllowed) and the Allow
+// header. Method and SmartMethod are recognized in a matcher or in the
+// members of an All, which allows the methods allowed by all of them, or of
+// a One, which allows the methods allowed by any of its matching members.
+func AllowedMethods(m Matcher, r *http.Request) ([]string, bool) {
+	methods, ok := allowedMethods(m, r)
+	return methods, ok && methods != nil
+}
+
+// intersectMethods returns the methods in both lists, in the order of a.
+func intersectMethods(a, b []string) []string {
+	var methods []string
+	for _, v := range a {
+		for _, w := range b {
+			if v == w {
+				methods = append(methods, v)
+				break
+			}
+		}
+	}
+	return methods
+}
+
+// unionMethods appends the methods of b that are not in a.
+func unionMethods(a, b []string) []string {
+	for _, v := range b {
+		if intersectMethods(a, []string{v}) == nil {
+			a = append(a, v)
+		}
+	}
+	return a
+}
+
+// allowedMethods returns the methods allowed by the matcher, or nil if it
+// doesn't check the method, and whether the rest of the matcher matches.
+func allowedMethods(m Matcher, r *http.Request) ([]string, bool) {
+	switch v := m.(type) {
+	case Method:
+		return v, true
+	case SmartMethod:
+		return v.Allowed(), true
+	case All:
+		var methods []string
+		for _, sub := range v {
+			allowed, ok := allowedMethods(sub, r)
+			if !ok {
+				return nil, false
+			}
+			if allowed == nil {
+				continue
+			}
+			if methods != nil {
+				allowed = intersectMethods(methods, allowed)
+			}
+			if len(allowed) == 0 {
+				// No method is allowed by all the members.
+				return nil, false
+			}
+			methods = allowed
+		}
+		return methods, true
+	case One:
+		var methods []string
+		var matched bool
+		for _, sub := range v {
+			allowed, ok := allowedMethods(sub, r)
+			if !ok {
+				continue
+			}
+			if allowed == nil {
+				// This member allows any method.
+				return nil, true
+			}
+			methods, matched = unionMethods(methods, allowed), true
+		}
+		return methods, matched
+	case *RouteSpec:
+		if v.Err() != nil {
+			return nil, false
+		}
+		return allowedMethods(v.Matcher(), r)
+	case *Route:
+		return allowedMethods(v.Matcher, r)
+	}
+	return nil, m.Match(r)
+}
@@ -0,0 +1,94 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSmartMethod(t *testing.T) {
+	const name = "SmartMethod"
+	matcher := NewSmartMethod([]string{"get", "post"})
+	tests := []struct {
+		method string
+		expect bool
+	}{
+		{"GET", true},
+		{"POST", true},
+		{"HEAD", true},
+		{"OPTIONS", true},
+		{"PUT", false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, matcher, r, v.expect)
+	}
+	expect := []string{"GET", "POST", "HEAD", "OPTIONS"}
+	if allowed := matcher.Allowed(); !reflect.DeepEqual(allowed, expect) {
+		t.Errorf("expected %v, got %v", expect, allowed)
+	}
+
+	r, _ := http.NewRequest("OPTIONS", "http://domain.com/", nil)
+	result := Result{}
+	matcher.Extract(&result, r)
+	if result.Handler == nil {
+		t.Fatal("expected OPTIONS handler")
+	}
+	w := httptest.NewRecorder()
+	result.Handler.ServeHTTP(w, r)
+	if allow := w.Header().Get("Allow"); w.Code != http.StatusNoContent || allow != "GET, POST, HEAD, OPTIONS" {
+		t.Errorf("unexpected OPTIONS response %d %q", w.Code, allow)
+	}
+}
+
+func TestAllowedMethods(t *testing.T) {
+	matcher := NewAll([]Matcher{NewMethod([]string{"PUT", "DELETE"}), NewPath("/a")})
+	tests := []struct {
+		method  string
+		path    string
+		allowed []string
+		ok      bool
+	}{
+		{"GET", "/a", []string{"PUT", "DELETE"}, true},
+		{"GET", "/b", nil, false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed, ok := AllowedMethods(matcher, r)
+		if ok != v.ok || !reflect.DeepEqual(allowed, v.allowed) {
+			t.Errorf("%s %s: expected %v %v, got %v %v", v.method, v.path,
+				v.allowed, v.ok, allowed, ok)
+		}
+	}
+	r, _ := http.NewRequest("GET", "http://domain.com/a", nil)
+	if _, ok := AllowedMethods(NewPath("/a"), r); ok {
+		t.Errorf("expected false for matcher without method")
+	}
+	matchers := []struct {
+		m       Matcher
+		allowed []string
+		ok      bool
+	}{
+		{NewAll([]Matcher{NewMethod([]string{"GET", "POST"}), NewMethod([]string{"POST"})}), []string{"POST"}, true},
+		{NewAll([]Matcher{NewMethod([]string{"GET"}), NewMethod([]string{"POST"})}), nil, false},
+		{NewOne([]Matcher{NewMethod([]string{"PUT"}), NewAll([]Matcher{NewMethod([]string{"POST", "PUT"}), NewPath("/a")})}), []string{"PUT", "POST"}, true},
+		{NewOne([]Matcher{NewAll([]Matcher{NewMethod([]string{"POST"}), NewPath("/b")})}), nil, false},
+	}
+	for _, v := range matchers {
+		allowed, ok := AllowedMethods(v.m, r)
+		if ok != v.ok || !reflect.DeepEqual(allowed, v.allowed) {
+			t.Errorf("%v: expected %v %v, got %v %v", v.m, v.allowed, v.ok, allowed, ok)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MetricLabels identifies what a metric observation refers to.
+type MetricLabels struct {
+	// Route is the route name given to NewMetricsHook.
+	Route string
+	// Matcher describes the matcher or builder, as returned by fmt.Sprint.
+	Matcher string
+}
+
+// Metrics records match and build metrics. It can be implemented using any
+// metrics library; for example, with Prometheus counters and histograms
+// labeled by route and matcher.
+type Metrics interface {
+	// ObserveMatch records a match attempt, its result and the time it took.
+	ObserveMatch(labels MetricLabels, matched bool, elapsed time.Duration)
+	// ObserveBuild records a URL build and its result.
+	ObserveBuild(labels MetricLabels, err error)
+}
+
+// NewMetricsHook returns a hook that records metrics for the given route.
+//
+// Install it using RouteSpec.Hook to record metrics for a whole route, or
+// using Instrument to record them for each matcher in a composite one.
+func NewMetricsHook(route string, m Metrics) Hook {
+	return &metricsHook{route: route, metrics: m}
+}
+
+// metricsHook is a Hook that sends observations to a Metrics.
+type metricsHook struct {
+	route   string
+	metrics Metrics
+}
+
+func (h *metricsHook) OnMatchStart(m Matcher, r *http.Request) {
+}
+
+func (h *metricsHook) OnMatchEnd(m Matcher, r *http.Request, matched bool, elapsed time.Duration) {
+	h.metrics.ObserveMatch(h.labels(m), matched, elapsed)
+}
+
+func (h *metricsHook) OnBuild(b Builder, u *url.URL, err error) {
+	h.metrics.ObserveBuild(h.labels(b), err)
+}
+
+// labels returns the labels for a matcher or builder.
+func (h *metricsHook) labels(v interface{}) MetricLabels {
+	return MetricLabels{Route: h.route, Matcher: fmt.Sprint(v)}
+}
@@ -0,0 +1,42 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+)
+
+// Middleware wraps a handler; for example, to log requests or check
+// authentication.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps a handler with the given middlewares. The first middleware is
+// the outermost one, so it is called first.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Use appends middlewares to the route. The handler of a matching request is
+// wrapped with the router middlewares and then the route ones, in order.
+func (r *Route) Use(middlewares ...Middleware) *Route {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// Use appends middlewares to the router. They wrap the handlers of all
+// routes, before the route middlewares.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Wrap wraps the result handler with the given middlewares, if it is set.
+func (r *Result) Wrap(middlewares ...Middleware) {
+	if r.Handler != nil {
+		r.Handler = Chain(r.Handler, middlewares...)
+	}
+}
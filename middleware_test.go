@@ -0,0 +1,51 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tag := func(s string) Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, s+" ")
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+	router := NewRouter()
+	router.Use(tag("router"))
+	router.HandleFunc("a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "a")
+	}, NewPath("/a")).Use(tag("route1"), tag("route2"))
+	router.HandleFunc("b", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "b")
+	}, NewPath("/b"))
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/a", "router route1 route2 a"},
+		{"/b", "router b"},
+		{"/c", "404 page not found\n"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Body.String() != v.body {
+			t.Errorf("%s: expected %q, got %q", v.path, v.body, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// NewMigration returns a migration from URLs matched by from to URLs built
+// by to. The variables extracted by from are passed through mapping, if it
+// is not nil, before building.
+//
+// The from matcher should also be an Extractor to pass variables along.
+func NewMigration(from Matcher, to Builder, mapping func(url.Values) (url.Values, error)) *Migration {
+	return &Migration{
+		From:    from,
+		To:      to,
+		Mapping: mapping,
+		Code:    http.StatusMovedPermanently,
+	}
+}
+
+// Migration pairs a matcher for an old URL scheme with a builder for a new
+// one, to redirect or rewrite URLs when they are restructured.
+//
+// It is a Matcher and an Extractor: when used in a route, it sets a redirect
+// handler in the result, like PathRedirect.
+type Migration struct {
+	From    Matcher
+	To      Builder
+	Mapping func(url.Values) (url.Values, error)
+	Code    int // redirect status code
+}
+
+func (m *Migration) Match(r *http.Request) bool {
+	return m.From.Match(r)
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("Migration(%v, %v)", m.From, m.To)
+}
+
+// Extract sets a handler that redirects to the new URL. If the new URL
+// can't be built, the handler responds with an internal server error.
+func (m *Migration) Extract(result *Result, r *http.Request) {
+	if result.Handler == nil {
+		result.Handler = m.redirect(r)
+	}
+}
+
+// Handler returns a handler that redirects requests matching the old URL
+// scheme to the new one, and responds with "404 not found" otherwise.
+func (m *Migration) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Match(r) {
+			http.NotFound(w, r)
+			return
+		}
+		m.redirect(r).ServeHTTP(w, r)
+	})
+}
+
+// Rewrite returns the new URL for an old one. The new URL keeps the scheme,
+// host, query and fragment of the old one unless the builder sets them.
+func (m *Migration) Rewrite(old *url.URL) (*url.URL, error) {
+	r := &http.Request{Method: "GET", URL: old, Host: old.Host,
+		Header: http.Header{}}
+	if !m.Match(r) {
+		return nil, fmt.Errorf("URL %q doesn't match the migration", old)
+	}
+	return m.rewrite(r)
+}
+
+// rewrite builds the new URL for a matching request.
+func (m *Migration) rewrite(r *http.Request) (*url.URL, error) {
+	result := &Result{}
+	if e, ok := m.From.(Extractor); ok {
+		e.Extract(result, r)
+	}
+	values := result.Values
+	if values == nil {
+		values = url.Values{}
+	}
+	if m.Mapping != nil {
+		var err error
+		if values, err = m.Mapping(values); err != nil {
+			return nil, err
+		}
+	}
+	u := *r.URL
+	u.Path, u.RawPath = "", ""
+	if err := m.To.Build(&u, values); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// redirect returns a handler that redirects a matching request.
+func (m *Migration) redirect(r *http.Request) http.Handler {
+	u, err := m.rewrite(r)
+	if err != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		})
+	}
+	return http.RedirectHandler(u.String(), m.Code)
+}
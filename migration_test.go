@@ -0,0 +1,46 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestMigration(t *testing.T) {
+	from := MustNewGorillaPath("/blog/{year:[0-9]+}/{slug}", false)
+	to := MustNewGorillaPath("/articles/{slug}", false)
+	m := NewMigration(from, to, func(v url.Values) (url.Values, error) {
+		delete(v, "year")
+		return v, nil
+	})
+	old, _ := url.Parse("http://domain.com/blog/2012/hello?ref=feed")
+	u, err := m.Rewrite(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "http://domain.com/articles/hello?ref=feed" {
+		t.Errorf("expected %q, got %q", "http://domain.com/articles/hello?ref=feed", s)
+	}
+	old, _ = url.Parse("http://domain.com/other")
+	if _, err := m.Rewrite(old); err == nil {
+		t.Errorf("expected error for non-matching URL")
+	}
+
+	r, err := http.NewRequest("GET", "http://domain.com/blog/2012/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, r)
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("expected code %d, got %d", http.StatusMovedPermanently, w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "http://domain.com/articles/hello" {
+		t.Errorf("expected redirect to %q, got %q", "http://domain.com/articles/hello", loc)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewMount returns a matcher that mounts another matcher under a Gorilla
+// path prefix, like "/api/{version}".
+func NewMount(prefix string, m Matcher, opts ...Option) (*Mount, error) {
+	p, err := NewGorillaPathPrefix(prefix, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Mount{Prefix: p, Matcher: m}, nil
+}
+
+// Mount matches a URL path prefix, and then the mounted matcher against the
+// request with the prefix stripped from the path. This allows to compose
+// route sets written independently of where they are mounted:
+//
+//	users := reverse.NewAll([]reverse.Matcher{
+//		reverse.MustNewGorillaPath("/users/{id}", false),
+//	})
+//	m, err := reverse.NewMount("/api/{version}", users)
+//	// m matches "/api/v1/users/42".
+//
+// Variables are extracted from both the prefix and the mounted matcher, and
+// built URLs have the prefix prepended.
+type Mount struct {
+	Prefix  *GorillaPathPrefix
+	Matcher Matcher
+}
+
+func (m *Mount) Match(r *http.Request) bool {
+	stripped, ok := m.Strip(r)
+	return ok && m.Matcher.Match(stripped)
+}
+
+func (m *Mount) String() string {
+	return fmt.Sprintf("Mount(%q, %v)", m.Prefix.pattern, m.Matcher)
+}
+
+// Extract returns the variables extracted from the prefix and by the mounted
+// matcher. The result pattern is the prefix joined with the mounted one.
+func (m *Mount) Extract(result *Result, r *http.Request) {
+	stripped, ok := m.Strip(r)
+	if !ok {
+		return
+	}
+	pattern := result.Pattern
+	m.Prefix.Extract(result, r)
+	result.Pattern = ""
+	extractMatcher(m.Matcher, result, stripped)
+	if pattern == "" {
+		pattern = joinPath(m.Prefix.pattern, result.Pattern)
+	}
+	result.Pattern = pattern
+}
+
+// Build builds the URL calling the mounted matcher builders, and prepends
+// the prefix built using the same values.
+func (m *Mount) Build(u *url.URL, values url.Values) error {
+	prefix := &url.URL{}
+	if err := m.Prefix.Build(prefix, values); err != nil {
+		return err
+	}
+	if err := buildMatcher(m.Matcher, u, values); err != nil {
+		return err
+	}
+	u.Path = joinPath(prefix.Path, u.Path)
+	if u.RawPath != "" || prefix.RawPath != "" {
+		u.RawPath = joinPath(prefix.EscapedPath(), u.EscapedPath())
+	}
+	return nil
+}
+
+// Strip returns a shallow copy of the request with the matched prefix
+// removed from the URL path. It returns false if the prefix doesn't match
+// whole path segments.
+func (m *Mount) Strip(r *http.Request) (*http.Request, bool) {
+	path := m.Prefix.opts.path(r)
+	loc := m.Prefix.compiled.FindStringIndex(path)
+	if loc == nil {
+		return nil, false
+	}
+	// The prefix must end at a segment boundary: "/api" doesn't match
+	// "/apiusers".
+	rest := path[loc[1]:]
+	if !strings.HasPrefix(rest, "/") {
+		if rest != "" && !strings.HasSuffix(path[:loc[1]], "/") {
+			return nil, false
+		}
+		rest = "/" + rest
+	}
+	u := *r.URL
+	if err := m.Prefix.opts.setPath(&u, rest); err != nil {
+		return nil, false
+	}
+	stripped := r.WithContext(r.Context())
+	stripped.URL = &u
+	return stripped, true
+}
+
+// Handler returns a handler that calls h with the prefix stripped from the
+// request path, or replies with 404 if the prefix doesn't match.
+func (m *Mount) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stripped, ok := m.Strip(r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, stripped)
+	})
+}
+
+// joinPath joins a path prefix and a path, with a single slash between them.
+func joinPath(prefix, path string) string {
+	if path == "" || path == "/" && prefix != "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
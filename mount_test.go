@@ -0,0 +1,89 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	const name = "Mount"
+	users := NewAll([]Matcher{MustNewGorillaPath("/users/{id:[0-9]+}", false)})
+	m, err := NewMount("/api/{version}", users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   string
+		expect bool
+		values url.Values
+	}{
+		{"/api/v1/users/42", true, url.Values{"version": {"v1"}, "id": {"42"}}},
+		{"/api/v1/users/abc", false, nil},
+		{"/users/42", false, nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, m, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		m.Extract(&result, r)
+		if !equalValues(v.values, result.Values) {
+			t.Errorf("%s: expected %v, got %v", name, v.values, result.Values)
+		}
+		u := &url.URL{}
+		if err := m.Build(u, result.Values); err != nil {
+			t.Errorf("%s: error building URL: %v", name, err)
+		} else if u.Path != v.path {
+			t.Errorf("%s: expected %q, got %q", name, v.path, u.Path)
+		}
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	r, _ := http.NewRequest("GET", "http://domain.com/api/v2/users/7?a=b", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "/users/7" {
+		t.Errorf("expected stripped path %q, got %q", "/users/7", w.Body.String())
+	}
+	if r.URL.Path != "/api/v2/users/7" {
+		t.Errorf("original request was modified: %q", r.URL.Path)
+	}
+}
+
+func TestMountSegmentBoundary(t *testing.T) {
+	const name = "Mount"
+	tests := []struct {
+		prefix string
+		path   string
+		expect bool
+	}{
+		{"/api", "/api/users", true},
+		{"/api", "/apiusers", false},
+		{"/api/", "/api/users", true},
+	}
+	for _, v := range tests {
+		m, err := NewMount(v.prefix, NewPath("/users"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, name, m, r, v.expect)
+	}
+}
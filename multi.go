@@ -5,7 +5,10 @@
 package reverse
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 // All ------------------------------------------------------------------------
@@ -27,6 +30,38 @@ func (m All) Match(r *http.Request) bool {
 	return true
 }
 
+// MatchInfo is like Match, but the members share the cached information.
+func (m All) MatchInfo(info *RequestInfo) bool {
+	for _, v := range m {
+		if !MatchInfo(v, info) {
+			return false
+		}
+	}
+	return true
+}
+
+// Extract calls Extract on every matcher that implements Extractor.
+func (m All) Extract(result *Result, r *http.Request) {
+	for _, v := range m {
+		extractMatcher(v, result, r)
+	}
+}
+
+// Build calls Build on every matcher that implements Builder, in order. It
+// stops at the first error.
+func (m All) Build(u *url.URL, values url.Values) error {
+	for _, v := range m {
+		if err := buildMatcher(v, u, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m All) String() string {
+	return "All(" + formatMatchers(m) + ")"
+}
+
 // One ------------------------------------------------------------------------
 
 // NewOne returns a group of matchers that succeeds if one of them matches.
@@ -38,10 +73,99 @@ func NewOne(matchers []Matcher) One {
 type One []Matcher
 
 func (m One) Match(r *http.Request) bool {
-	for _, v := range m {
+	return m.MatchIndex(r) != -1
+}
+
+// MatchIndex returns the index of the first matching matcher, or -1 if none
+// matches.
+func (m One) MatchIndex(r *http.Request) int {
+	for k, v := range m {
 		if v.Match(r) {
+			return k
+		}
+	}
+	return -1
+}
+
+// MatchInfo is like Match, but the members share the cached information.
+func (m One) MatchInfo(info *RequestInfo) bool {
+	for _, v := range m {
+		if MatchInfo(v, info) {
 			return true
 		}
 	}
 	return false
 }
+
+// Extract calls Extract on the first matching matcher.
+func (m One) Extract(result *Result, r *http.Request) {
+	if i := m.MatchIndex(r); i != -1 {
+		extractMatcher(m[i], result, r)
+	}
+}
+
+func (m One) String() string {
+	return "One(" + formatMatchers(m) + ")"
+}
+
+// Not ------------------------------------------------------------------------
+
+// NewNot returns a matcher that inverts the result of the given one.
+func NewNot(matcher Matcher) Not {
+	return Not{matcher}
+}
+
+// Not matches if the wrapped matcher doesn't match.
+type Not struct {
+	Matcher Matcher
+}
+
+func (m Not) Match(r *http.Request) bool {
+	return !m.Matcher.Match(r)
+}
+
+func (m Not) MatchInfo(info *RequestInfo) bool {
+	return !MatchInfo(m.Matcher, info)
+}
+
+func (m Not) String() string {
+	return "Not(" + fmt.Sprint(m.Matcher) + ")"
+}
+
+// Helpers --------------------------------------------------------------------
+
+// formatMatchers returns a comma-separated list of matcher descriptions.
+func formatMatchers(matchers []Matcher) string {
+	parts := make([]string, len(matchers))
+	for k, v := range matchers {
+		parts[k] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// walkMatchers calls fn for the given matcher and, recursively, for the
+// matchers it is composed of.
+func walkMatchers(m Matcher, fn func(Matcher)) {
+	fn(m)
+	switch v := m.(type) {
+	case All:
+		for _, sub := range v {
+			walkMatchers(sub, fn)
+		}
+	case One:
+		for _, sub := range v {
+			walkMatchers(sub, fn)
+		}
+	case Not:
+		walkMatchers(v.Matcher, fn)
+	case *RouteSpec:
+		walkMatchers(v.Matcher(), fn)
+	case *Hooked:
+		walkMatchers(v.Unwrap(), fn)
+	case *Route:
+		walkMatchers(v.Matcher, fn)
+	case *Mount:
+		walkMatchers(v.Prefix, fn)
+		walkMatchers(v.Matcher, fn)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIParameter describes a path variable for an OpenAPI specification.
+type OpenAPIParameter struct {
+	Name string
+	// Type is the schema type hint: the type of a built-in Gorilla variable
+	// type, like "integer" for `{id:int}`, or else derived from the variable
+	// regexp: "integer" for digits only, like `[0-9]+`, or "string".
+	Type string
+	// Pattern is the variable regexp.
+	Pattern string
+	// Required is false for optional variables.
+	Required bool
+}
+
+// OpenAPIPath returns the pattern as an OpenAPI path template, like
+// "/articles/{id}".
+func (m *GorillaPath) OpenAPIPath() string {
+	return strings.Replace(gorillaTemplate(m.pattern), "}?", "}", -1)
+}
+
+// OpenAPIParameters returns the path variables, in order.
+func (m *GorillaPath) OpenAPIParameters() []OpenAPIParameter {
+	params := openAPIParameters(&m.Regexp)
+	types := map[string]string{}
+	segments, _ := ParseGorillaTemplate(m.pattern)
+	for _, seg := range segments {
+		if t, ok := lookupVarType(seg.Pattern); ok && t.openAPI != "" {
+			types[seg.Name] = t.openAPI
+		}
+	}
+	for k, v := range params {
+		if typ, ok := types[v.Name]; ok {
+			params[k].Type = typ
+		}
+	}
+	return params
+}
+
+// OpenAPIPath returns the pattern as an OpenAPI path template, like
+// "/articles/{id}". Positional variables are named "param1", "param2", etc.
+// by their position among all variables.
+func (m *RegexpPath) OpenAPIPath() string {
+	names := openAPINames(&m.Regexp)
+	var b strings.Builder
+	var group int
+	tpl := m.template
+	for i := 0; i < len(tpl); i++ {
+		if tpl[i] != '%' || i+1 == len(tpl) {
+			b.WriteByte(tpl[i])
+			continue
+		}
+		i++
+		if tpl[i] == 's' && group < len(names) {
+			b.WriteString("{" + names[group] + "}")
+			group++
+		} else {
+			b.WriteByte(tpl[i])
+		}
+	}
+	return b.String()
+}
+
+// OpenAPIParameters returns the path variables, in order. Positional
+// variables are named like in OpenAPIPath.
+func (m *RegexpPath) OpenAPIParameters() []OpenAPIParameter {
+	return openAPIParameters(&m.Regexp)
+}
+
+// openAPINames returns the variable names, naming positional ones by their
+// position.
+func openAPINames(r *Regexp) []string {
+	names := make([]string, len(r.groups))
+	for k, v := range r.groups {
+		if v == "" {
+			v = "param" + strconv.Itoa(k+1)
+		}
+		names[k] = v
+	}
+	return names
+}
+
+// openAPIParameters returns the parameters for the outermost groups of a
+// regexp.
+func openAPIParameters(r *Regexp) []OpenAPIParameter {
+	patterns := map[int]*syntax.Regexp{}
+	if re, err := syntax.Parse(r.compiled.String(), syntax.Perl); err == nil {
+		captures(re, patterns)
+	}
+	names := openAPINames(r)
+	params := make([]OpenAPIParameter, len(names))
+	for k, name := range names {
+		param := OpenAPIParameter{
+			Name:     name,
+			Type:     "string",
+			Required: !r.optional[k],
+		}
+		if re := patterns[r.indices[k]]; re != nil {
+			param.Pattern = re.String()
+			if digitsOnly(re) {
+				param.Type = "integer"
+			}
+		}
+		params[k] = param
+	}
+	return params
+}
+
+// captures stores the subexpressions of the capturing groups by index.
+func captures(re *syntax.Regexp, patterns map[int]*syntax.Regexp) {
+	if re.Op == syntax.OpCapture {
+		patterns[re.Cap] = re.Sub[0]
+	}
+	for _, sub := range re.Sub {
+		captures(sub, patterns)
+	}
+}
+
+// digitsOnly returns whether a regexp only matches decimal digits.
+func digitsOnly(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return len(re.Rune) != 0
+	case syntax.OpCharClass:
+		for i := 0; i < len(re.Rune); i += 2 {
+			if re.Rune[i] < '0' || re.Rune[i+1] > '9' {
+				return false
+			}
+		}
+		return len(re.Rune) != 0
+	case syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest,
+		syntax.OpRepeat, syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !digitsOnly(sub) {
+				return false
+			}
+		}
+		return len(re.Sub) != 0
+	}
+	return false
+}
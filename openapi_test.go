@@ -0,0 +1,43 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	gorillaPath := MustNewGorillaPath("/articles/{category}/{id:[0-9]+}?", false)
+	if s := gorillaPath.OpenAPIPath(); s != "/articles/{category}/{id}" {
+		t.Errorf("expected %q, got %q", "/articles/{category}/{id}", s)
+	}
+	expect := []OpenAPIParameter{
+		{Name: "category", Type: "string", Pattern: "[^/]+", Required: true},
+		{Name: "id", Type: "integer", Pattern: "[0-9]+"},
+	}
+	if params := gorillaPath.OpenAPIParameters(); !reflect.DeepEqual(params, expect) {
+		t.Errorf("expected %v, got %v", expect, params)
+	}
+
+	typed := MustNewGorillaPath("/{id:int}/{flag:bool}/{day:time:2006-01-02}", false)
+	for k, typ := range []string{"integer", "boolean", "string"} {
+		if params := typed.OpenAPIParameters(); params[k].Type != typ {
+			t.Errorf("%s: expected %q, got %q", params[k].Name, typ, params[k].Type)
+		}
+	}
+
+	regexpPath := MustNewRegexpPath(`^/users/(\d{4})/(?P<name>[a-z]+)$`)
+	if s := regexpPath.OpenAPIPath(); s != "/users/{param1}/{name}" {
+		t.Errorf("expected %q, got %q", "/users/{param1}/{name}", s)
+	}
+	expect = []OpenAPIParameter{
+		{Name: "param1", Type: "integer", Pattern: "[0-9]{4}", Required: true},
+		{Name: "name", Type: "string", Pattern: "[a-z]+", Required: true},
+	}
+	if params := regexpPath.OpenAPIParameters(); !reflect.DeepEqual(params, expect) {
+		t.Errorf("expected %v, got %v", expect, params)
+	}
+}
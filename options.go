@@ -0,0 +1,253 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Option configures the matchers created by the pattern constructors:
+// NewRegexpHost, NewRegexpPath, NewGorillaHost, NewGorillaPath and
+// NewGorillaPathPrefix, or by a RouteSpec using RouteSpec.Options. Options
+// that don't apply to a matcher are ignored.
+type Option func(*options)
+
+// options stores the settings applied by Option functions.
+type options struct {
+	strictSlash    bool
+	slashPolicy    SlashPolicy
+	caseFold       bool
+	lowerCase      bool
+	encoded        bool
+	stripQuery     bool
+	groupLiterals  bool
+	defaultPattern string
+	redirectCode   int
+	duplicates     DuplicatePolicy
+	escaping       Escaping
+	cache          *RegexpCache
+	vars           *VarRegistry
+	strictTemplate bool
+	flags          syntax.Flags
+	customFlags    bool
+	syntax         string
+}
+
+// WithStrictSlash sets whether a path matcher redirects requests that differ
+// from the pattern only by the trailing slash.
+func WithStrictSlash(strictSlash bool) Option {
+	return func(o *options) {
+		o.strictSlash = strictSlash
+	}
+}
+
+// WithSlashPolicy sets how a path matcher treats the trailing slash of the
+// request path; see SlashPolicy. It takes precedence over WithStrictSlash.
+func WithSlashPolicy(policy SlashPolicy) Option {
+	return func(o *options) {
+		o.slashPolicy = policy
+	}
+}
+
+// slash returns the trailing slash policy for a path pattern.
+func (o options) slash(pattern string) SlashPolicy {
+	if o.slashPolicy == SlashStrict && o.strictSlash {
+		return patternSlashPolicy(pattern)
+	}
+	return o.slashPolicy
+}
+
+// WithCaseFold makes the pattern match case-insensitively.
+func WithCaseFold() Option {
+	return func(o *options) {
+		o.caseFold = true
+	}
+}
+
+// WithLowerCase makes path matchers write the literal parts of built paths
+// in lower-case, so that a case-insensitive route has a canonical URL;
+// variable values are kept as they are. It requires WithCaseFold, otherwise
+// the matcher wouldn't match its own URLs.
+func WithLowerCase() Option {
+	return func(o *options) {
+		o.lowerCase = true
+	}
+}
+
+// WithEncoding makes path matchers match against the escaped URL path
+// (as returned by url.URL.EscapedPath), so that encoded characters like
+// "%2F" can be told apart from their decoded form. Built paths are then
+// treated as escaped, too.
+func WithEncoding() Option {
+	return func(o *options) {
+		o.encoded = true
+	}
+}
+
+// WithStripQuery drops the URL query from trailing slash redirects.
+func WithStripQuery() Option {
+	return func(o *options) {
+		o.stripQuery = true
+	}
+}
+
+// WithDefaultPattern sets the pattern used for Gorilla variables declared
+// without one, like `{name}`. The default is "[^/]+" for paths and "[^.]+"
+// for hosts.
+func WithDefaultPattern(pattern string) Option {
+	return func(o *options) {
+		o.defaultPattern = pattern
+	}
+}
+
+// WithRedirectCode sets the status code used for trailing slash redirects.
+// The default is 301 (http.StatusMovedPermanently).
+func WithRedirectCode(code int) Option {
+	return func(o *options) {
+		o.redirectCode = code
+	}
+}
+
+// WithGroupLiterals keeps the literals inside capturing groups when
+// building, so that only the variable part of a group like `(user-\d+)` must
+// be given ("42" builds "user-42"). Extracted values don't include the kept
+// literals either.
+//
+// Only groups made of literals around a single variable part are affected;
+// other groups become a placeholder entirely.
+func WithGroupLiterals() Option {
+	return func(o *options) {
+		o.groupLiterals = true
+	}
+}
+
+// WithEscaping sets how variable values are escaped when building, and
+// unescaped when extracted, so that values like "a/b" can be used. The
+// default is NoEscaping.
+//
+// Path matchers using PathEscaping match and build escaped paths, like
+// WithEncoding. Query matchers reject it, since query values are already
+// escaped.
+func WithEscaping(escaping Escaping) Option {
+	return func(o *options) {
+		o.escaping = escaping
+	}
+}
+
+// checkQuery returns an error if the options can't be used by query
+// matchers: url.Values already escapes and unescapes the values, so any
+// escaping would be applied twice.
+func (o options) checkQuery() error {
+	if o.escaping != NoEscaping {
+		return errors.New("WithEscaping can't be used with query matchers")
+	}
+	return nil
+}
+
+// WithStrictTemplate makes compiling fail with a *TemplateError when the
+// pattern has constructs that the reverse template can't represent
+// faithfully, instead of building URLs that may not match: alternations,
+// character classes and required quantified expressions outside capturing
+// groups, like `(?:a|b)`, `[0-9]` or `a+`, and anchors in the middle of the
+// pattern, like `/a$/b`. Optional expressions, like `/?`, are left out.
+func WithStrictTemplate() Option {
+	return func(o *options) {
+		o.strictTemplate = true
+	}
+}
+
+// WithDuplicates sets how duplicated group names are handled.
+func WithDuplicates(policy DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = policy
+	}
+}
+
+// WithRegexpCache makes the pattern constructors compile their regexps using
+// the given cache, so that creating matchers with the same patterns many
+// times, like once per tenant, parses and compiles each regexp only once.
+func WithRegexpCache(c *RegexpCache) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithVarRegistry sets the registry of variable transformers applied to
+// extracted values and to the values used to build URLs.
+func WithVarRegistry(reg *VarRegistry) Option {
+	return func(o *options) {
+		o.vars = reg
+	}
+}
+
+// newOptions returns the options resulting from applying the given ones
+// to the defaults.
+func newOptions(opts []Option) options {
+	o := options{redirectCode: http.StatusMovedPermanently}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// settings returns the options without the cache, which doesn't change how
+// matchers behave.
+func (o options) settings() options {
+	o.cache = nil
+	return o
+}
+
+// compile compiles a regexp pattern using the options.
+func (o options) compile(pattern string) (*Regexp, error) {
+	if o.lowerCase && !o.caseFold {
+		return nil, errors.New("WithLowerCase requires WithCaseFold")
+	}
+	r, err := compileRegexp(pattern, o)
+	if err == nil && o.lowerCase {
+		r.template = lowerTemplate(r.template)
+	}
+	return r, err
+}
+
+// lowerTemplate returns the template with its literals in lower-case. Runes
+// whose lower-case form has a different length are kept, so that the byte
+// offsets of the template sections remain valid.
+func lowerTemplate(tpl string) string {
+	return strings.Map(func(r rune) rune {
+		if l := unicode.ToLower(r); utf8.RuneLen(l) == utf8.RuneLen(r) {
+			return l
+		}
+		return r
+	}, tpl)
+}
+
+// path returns the request URL path, escaped if encoding is enabled.
+func (o options) path(r *http.Request) string {
+	if o.encoded || o.escaping == PathEscaping {
+		return r.URL.EscapedPath()
+	}
+	return r.URL.Path
+}
+
+// setPath writes a built path to the given URL, unescaping it first if
+// encoding is enabled.
+func (o options) setPath(u *url.URL, path string) error {
+	if !o.encoded && o.escaping != PathEscaping {
+		u.Path = path
+		return nil
+	}
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return err
+	}
+	u.Path, u.RawPath = unescaped, path
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	m, err := NewGorillaPath("/{name}/", false, WithStrictSlash(true),
+		WithCaseFold(), WithDefaultPattern("[a-z]+"),
+		WithRedirectCode(http.StatusPermanentRedirect), WithStripQuery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("POST", "http://domain.com/FOO?a=b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	if result.Handler == nil {
+		t.Fatal("GorillaPath: expected redirect handler")
+	}
+	w := httptest.NewRecorder()
+	result.Handler.ServeHTTP(w, r)
+	if w.Code != http.StatusPermanentRedirect {
+		t.Errorf("expected code %d, got %d", http.StatusPermanentRedirect, w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "http://domain.com/FOO/" {
+		t.Errorf("expected redirect to %q, got %q", "http://domain.com/FOO/", loc)
+	}
+	r, err = http.NewRequest("GET", "http://domain.com/123/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, false)
+}
+
+func TestEncodingOption(t *testing.T) {
+	m, err := NewGorillaPath("/files/{name}", false, WithEncoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/files/a%2Fb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	expect := url.Values{"name": {"a%2Fb"}}
+	if !equalValues(expect, result.Values) {
+		t.Errorf("expected %v, got %v", expect, result.Values)
+	}
+	u := url.URL{}
+	if err := m.Build(&u, result.Values); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/files/a/b" || u.EscapedPath() != "/files/a%2Fb" {
+		t.Errorf("expected %q, got %q", "/files/a%2Fb", u.EscapedPath())
+	}
+}
+
+func TestLowerCaseOption(t *testing.T) {
+	m := MustNewGorillaPath("/Users/{name}", false, WithCaseFold(),
+		WithLowerCase())
+	r, err := http.NewRequest("GET", "http://domain.com/USERS/Bob", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	u := &url.URL{}
+	if err := m.Build(u, result.Values); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/users/Bob" {
+		t.Errorf("expected %q, got %q", "/users/Bob", u.Path)
+	}
+	if _, err := NewGorillaPath("/Users/{name}", false, WithLowerCase()); err == nil {
+		t.Errorf("expected error for WithLowerCase without WithCaseFold")
+	}
+}
+
+func TestEscapingOption(t *testing.T) {
+	m := MustNewGorillaPath("/files/{name}", false, WithEscaping(PathEscaping))
+	r, err := http.NewRequest("GET", "http://domain.com/files/a%2Fb%20c", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	expect := url.Values{"name": {"a/b c"}}
+	if !equalValues(expect, result.Values) {
+		t.Errorf("expected %v, got %v", expect, result.Values)
+	}
+	u := &url.URL{}
+	if err := m.Build(u, result.Values); err != nil {
+		t.Fatal(err)
+	}
+	if u.EscapedPath() != "/files/a%2Fb%20c" {
+		t.Errorf("expected %q, got %q", "/files/a%2Fb%20c", u.EscapedPath())
+	}
+
+	re, err := CompileRegexpWith(`^q=(?P<q>.+)$`, WithEscaping(QueryEscaping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := re.Revert(url.Values{"q": {"a&b c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "q=a%26b+c" {
+		t.Errorf("expected %q, got %q", "q=a%26b+c", s)
+	}
+	if v := re.Values(s).Get("q"); v != "a&b c" {
+		t.Errorf("expected %q, got %q", "a&b c", v)
+	}
+	// Query values are already unescaped by url.Values.
+	if _, err := NewRegexpQuery("q", `^(?P<q>.+)$`, WithEscaping(QueryEscaping)); err == nil {
+		t.Errorf("RegexpQuery: expected error for WithEscaping")
+	}
+	if _, err := NewGorillaQuery("q", "{q}", WithEscaping(QueryEscaping)); err == nil {
+		t.Errorf("GorillaQuery: expected error for WithEscaping")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Attribute is a key/value pair to annotate traces and metrics. It can be
+// converted to an OpenTelemetry attribute.KeyValue using attribute.String.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// RouteAttributes returns the trace attributes for a request matched by the
+// given matcher: "http.route" with the low-cardinality path template, like
+// "/users/{id}", and one "http.route.var.<name>" attribute for each
+// extracted named variable.
+//
+// The route attribute is omitted if the matcher has no path matcher.
+func RouteAttributes(m Matcher, result *Result) []Attribute {
+	var attrs []Attribute
+	if route := RouteTemplate(m); route != "" {
+		attrs = append(attrs, Attribute{Key: "http.route", Value: route})
+	}
+	if result != nil {
+		walkMatchers(m, func(m Matcher) {
+			g, ok := m.(interface{ Groups() []string })
+			if !ok {
+				return
+			}
+			for _, name := range g.Groups() {
+				if v := result.Values[name]; name != "" && len(v) != 0 {
+					attrs = append(attrs, Attribute{
+						Key:   "http.route.var." + name,
+						Value: v[0],
+					})
+				}
+			}
+		})
+	}
+	return attrs
+}
+
+// RouteTemplate returns the path template for the first path matcher found
+// in the given matcher, with variables written as `{name}` and without their
+// patterns. Prefix matchers end with "*". It returns an empty string if no
+// path matcher is found.
+func RouteTemplate(m Matcher) string {
+	var route string
+	walkMatchers(m, func(m Matcher) {
+		if route != "" {
+			return
+		}
+		switch v := m.(type) {
+		case Path:
+			route = string(v)
+		case PathRedirect:
+			route = string(v)
+		case PathPrefix:
+			route = string(v) + "*"
+		case *GorillaPath:
+			route = gorillaTemplate(v.pattern)
+		case *GorillaPathPrefix:
+			route = gorillaTemplate(v.pattern) + "*"
+		case *RegexpPath:
+			route = regexpTemplate(&v.Regexp)
+		}
+	})
+	return route
+}
+
+// WithRouteAttributes returns a handler that calls set with the route
+// attributes of the request before calling h. The matcher variables are
+// extracted from the request.
+//
+// It is meant to add attributes to the current span; for example:
+//
+//	h = reverse.WithRouteAttributes(h, route, func(r *http.Request, attrs []reverse.Attribute) {
+//		span := trace.SpanFromContext(r.Context())
+//		for _, a := range attrs {
+//			span.SetAttributes(attribute.String(a.Key, a.Value))
+//		}
+//	})
+func WithRouteAttributes(h http.Handler, m Matcher, set func(*http.Request, []Attribute)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := &Result{}
+		if e, ok := m.(Extractor); ok {
+			e.Extract(result, r)
+		}
+		set(r, RouteAttributes(m, result))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// gorillaTemplate removes the variable patterns from a Gorilla pattern.
+func gorillaTemplate(tpl string) string {
+	segments, err := ParseGorillaTemplate(tpl)
+	if err != nil {
+		return tpl
+	}
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg.Kind == LiteralSegment {
+			b.WriteString(seg.Literal)
+			continue
+		}
+		b.WriteString("{" + seg.Name + "}")
+		if seg.Optional {
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// regexpTemplate returns the reverse template of a regexp with variables
+// written as `{name}`; positional variables are written as `{}`.
+func regexpTemplate(r *Regexp) string {
+	var b strings.Builder
+	var group int
+	tpl := r.template
+	for i := 0; i < len(tpl); i++ {
+		if tpl[i] != '%' || i+1 == len(tpl) {
+			b.WriteByte(tpl[i])
+			continue
+		}
+		i++
+		if tpl[i] == 's' && group < len(r.groups) {
+			b.WriteString("{" + r.groups[group] + "}")
+			group++
+		} else {
+			b.WriteByte(tpl[i])
+		}
+	}
+	return b.String()
+}
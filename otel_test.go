@@ -0,0 +1,52 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteAttributes(t *testing.T) {
+	tests := []struct {
+		matcher Matcher
+		expect  string
+	}{
+		{NewPath("/a"), "/a"},
+		{MustNewGorillaPath("/users/{id:[0-9]+}/{name}", false), "/users/{id}/{name}"},
+		{MustNewGorillaPathPrefix("/files/{dir}"), "/files/{dir}*"},
+		{MustNewRegexpPath(`^/items/(?P<id>\d+)/(\d+)%$`), "/items/{id}/{}%"},
+		{NewMethod([]string{"GET"}), ""},
+	}
+	for _, v := range tests {
+		if route := RouteTemplate(v.matcher); route != v.expect {
+			t.Errorf("%v: expected %q, got %q", v.matcher, v.expect, route)
+		}
+	}
+
+	spec := NewRouteSpec().PathPrefix("/api").Path("/users/{id:[0-9]+}")
+	var attrs []Attribute
+	h := WithRouteAttributes(http.NotFoundHandler(), spec, func(r *http.Request, a []Attribute) {
+		attrs = a
+	})
+	r, err := http.NewRequest("GET", "http://domain.com/api/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	expect := []Attribute{
+		{"http.route", "/api/users/{id}"},
+		{"http.route.var.id", "42"},
+	}
+	if len(attrs) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, attrs)
+	}
+	for k, v := range expect {
+		if attrs[k] != v {
+			t.Errorf("expected %v, got %v", v, attrs[k])
+		}
+	}
+}
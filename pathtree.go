@@ -0,0 +1,249 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewPathTree returns an empty path tree.
+func NewPathTree[T any]() *PathTree[T] {
+	return &PathTree[T]{}
+}
+
+// PathTree stores values for many Gorilla path patterns in a tree keyed by
+// path segments, similar to httprouter. A lookup walks the request path one
+// segment at a time, so its cost barely depends on the number of patterns;
+// regexps are only checked for variables with a pattern, like `{id:[0-9]+}`,
+// or segments mixing literals and variables.
+//
+// When several patterns match, static segments have priority over variables,
+// which have priority over catch-all variables; otherwise patterns added
+// first win.
+//
+// Variables only match within a segment, except catch-all variables, which
+// must be last. Optional variables are not supported.
+//
+// PathTree is a Matcher and Extractor for the URL path.
+type PathTree[T any] struct {
+	root treeNode[T]
+}
+
+// treeNode is a node in a path tree, for one path segment.
+type treeNode[T any] struct {
+	static   map[string]*treeNode[T]
+	params   []*treeParam[T]
+	catchAll *treeParam[T]
+	leaf     *treeLeaf[T]
+}
+
+// treeParam is an edge for a segment with variables.
+type treeParam[T any] struct {
+	key  string  // segment pattern
+	name string  // variable name, for segments with a single variable
+	re   *Regexp // regexp for the segment, or nil if any value matches
+	node *treeNode[T]
+	leaf *treeLeaf[T] // for catch-all variables
+}
+
+// treeLeaf is a pattern stored in a path tree.
+type treeLeaf[T any] struct {
+	value T
+	path  *GorillaPath
+}
+
+// Add stores a value for a Gorilla path pattern, replacing the value for
+// the same pattern if any. It returns the path matcher for the pattern,
+// which can be used to build URLs.
+func (t *PathTree[T]) Add(pattern string, value T, opts ...Option) (*GorillaPath, error) {
+	path, err := NewGorillaPath(pattern, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("Path pattern %q must start with a slash",
+			pattern)
+	}
+	o := newOptions(opts)
+	n := &t.root
+	segments := splitSegments(pattern[1:])
+	for k, s := range segments {
+		p, err := newTreeParam[T](s, o)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p == nil:
+			child := n.static[s]
+			if child == nil {
+				if n.static == nil {
+					n.static = map[string]*treeNode[T]{}
+				}
+				child = &treeNode[T]{}
+				n.static[s] = child
+			}
+			n = child
+		case p.node == nil:
+			if k != len(segments)-1 {
+				return nil, fmt.Errorf(
+					"Catch-all variable must be last in %q", pattern)
+			}
+			if n.catchAll != nil && n.catchAll.key != s {
+				return nil, fmt.Errorf(
+					"Conflicting catch-all variables in %q", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll = p
+			}
+			n.catchAll.leaf = &treeLeaf[T]{value: value, path: path}
+			return path, nil
+		default:
+			var found *treeParam[T]
+			for _, v := range n.params {
+				if v.key == s {
+					found = v
+				}
+			}
+			if found == nil {
+				found = p
+				n.params = append(n.params, p)
+			}
+			n = found.node
+		}
+	}
+	n.leaf = &treeLeaf[T]{value: value, path: path}
+	return path, nil
+}
+
+// Lookup returns the value stored for the pattern matching the path, its
+// path matcher and the extracted variables.
+func (t *PathTree[T]) Lookup(path string) (value T, m *GorillaPath, values url.Values, ok bool) {
+	if !strings.HasPrefix(path, "/") {
+		return value, nil, nil, false
+	}
+	values = url.Values{}
+	leaf := t.root.lookup(strings.Split(path[1:], "/"), values)
+	if leaf == nil {
+		return value, nil, nil, false
+	}
+	return leaf.value, leaf.path, values, true
+}
+
+func (t *PathTree[T]) Match(r *http.Request) bool {
+	_, _, _, ok := t.Lookup(r.URL.Path)
+	return ok
+}
+
+func (t *PathTree[T]) String() string {
+	return "PathTree"
+}
+
+// Extract returns the variables extracted from the URL path.
+func (t *PathTree[T]) Extract(result *Result, r *http.Request) {
+	if _, _, values, ok := t.Lookup(r.URL.Path); ok {
+		result.Values = mergeValues(result.Values, values)
+	}
+}
+
+// lookup returns the leaf matching the remaining path segments, adding the
+// extracted variables to values.
+func (n *treeNode[T]) lookup(segments []string, values url.Values) *treeLeaf[T] {
+	if len(segments) == 0 {
+		return n.leaf
+	}
+	s := segments[0]
+	if child := n.static[s]; child != nil {
+		if leaf := child.lookup(segments[1:], values); leaf != nil {
+			return leaf
+		}
+	}
+	for _, p := range n.params {
+		if p.re == nil && s == "" || p.re != nil && !p.re.MatchString(s) {
+			continue
+		}
+		if leaf := p.node.lookup(segments[1:], values); leaf != nil {
+			if p.re == nil {
+				values.Add(p.name, s)
+			} else {
+				for k, v := range p.re.Values(s) {
+					values[k] = append(values[k], v...)
+				}
+			}
+			return leaf
+		}
+	}
+	if p := n.catchAll; p != nil && p.leaf != nil {
+		values.Add(p.name, strings.Join(segments, "/"))
+		return p.leaf
+	}
+	return nil
+}
+
+// newTreeParam returns the edge for a segment pattern, or nil for a static
+// segment. Catch-all edges have no node.
+func newTreeParam[T any](s string, o options) (*treeParam[T], error) {
+	segments, err := ParseGorillaTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range segments {
+		if v.Optional {
+			return nil, fmt.Errorf(
+				"Optional variables are not supported in %q", s)
+		}
+		if strings.Contains(v.Pattern, "/") {
+			return nil, fmt.Errorf(
+				"Variables can't match slashes in %q", s)
+		}
+		if v.Pattern == "*" && len(segments) != 1 {
+			return nil, fmt.Errorf(
+				"Catch-all variable must be a whole segment in %q", s)
+		}
+	}
+	p := &treeParam[T]{key: s, node: &treeNode[T]{}}
+	switch {
+	case len(segments) == 0 || segments[0].Kind == LiteralSegment &&
+		len(segments) == 1:
+		return nil, nil
+	case len(segments) == 1 && segments[0].Pattern == "*":
+		p.name, p.node = segments[0].Name, nil
+		return p, nil
+	case len(segments) == 1 && segments[0].Pattern == "" &&
+		o.defaultPattern == "":
+		p.name = segments[0].Name
+		return p, nil
+	}
+	pattern, err := gorillaPattern(s, o.defaultPattern, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if p.re, err = o.compile(pattern); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// splitSegments splits a Gorilla pattern by the slashes outside of braces.
+func splitSegments(pattern string) []string {
+	var segments []string
+	var level, start int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+		case '/':
+			if level == 0 {
+				segments = append(segments, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, pattern[start:])
+}
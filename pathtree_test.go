@@ -0,0 +1,81 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestPathTree(t *testing.T) {
+	tree := NewPathTree[string]()
+	patterns := []struct {
+		pattern string
+		value   string
+	}{
+		{"/", "home"},
+		{"/users/new", "new user"},
+		{"/users/{id:[0-9]+}", "user by id"},
+		{"/users/{name}", "user by name"},
+		{"/users/{id:[0-9]+}/posts/{slug}.html", "post"},
+		{"/static/{path...}", "static"},
+		{"/files/{dir}/list", "files"},
+	}
+	paths := map[string]*GorillaPath{}
+	for _, v := range patterns {
+		path, err := tree.Add(v.pattern, v.value)
+		if err != nil {
+			t.Fatalf("%s: %v", v.pattern, err)
+		}
+		paths[v.value] = path
+	}
+	tests := []struct {
+		path   string
+		value  string
+		values url.Values
+		ok     bool
+	}{
+		{"/", "home", url.Values{}, true},
+		{"/users/new", "new user", url.Values{}, true},
+		{"/users/42", "user by id", url.Values{"id": {"42"}}, true},
+		{"/users/bob", "user by name", url.Values{"name": {"bob"}}, true},
+		{"/users/42/posts/hello.html", "post", url.Values{"id": {"42"}, "slug": {"hello"}}, true},
+		{"/static/css/a.css", "static", url.Values{"path": {"css/a.css"}}, true},
+		{"/static/", "static", url.Values{"path": {""}}, true},
+		{"/files/a/list", "files", url.Values{"dir": {"a"}}, true},
+		{"/files/a/b/list", "", nil, false},
+		{"/users/", "", nil, false},
+		{"/users/bob/posts/hello.html", "", nil, false},
+		{"/static", "", nil, false},
+		{"/other", "", nil, false},
+	}
+	for _, v := range tests {
+		value, path, values, ok := tree.Lookup(v.path)
+		if value != v.value || ok != v.ok || !equalValues(values, v.values) {
+			t.Errorf("%s: expected %q %v %v, got %q %v %v", v.path, v.value,
+				v.values, v.ok, value, values, ok)
+		}
+		r, _ := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		testMatcher(t, "PathTree", tree, r, v.ok)
+		if !ok {
+			continue
+		}
+		if path != paths[v.value] {
+			t.Errorf("%s: unexpected path matcher %v", v.path, path)
+		}
+		u := &url.URL{}
+		if err := path.Build(u, values); err != nil {
+			t.Errorf("%s: error building URL: %v", v.path, err)
+		} else if u.Path != v.path {
+			t.Errorf("%s: built %q", v.path, u.Path)
+		}
+	}
+	for _, pattern := range []string{"users", "/{a...}/b", "/{a}?", "/a-{b...}", "/{c:[a-z]+/[a-z]+}"} {
+		if _, err := tree.Add(pattern, ""); err == nil {
+			t.Errorf("%s: expected error", pattern)
+		}
+	}
+}
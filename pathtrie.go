@@ -0,0 +1,110 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+)
+
+// NewPathTrie returns an empty path trie.
+func NewPathTrie[T any]() *PathTrie[T] {
+	return &PathTrie[T]{}
+}
+
+// PathTrie stores values for many static paths and path prefixes, and finds
+// the one for a path in O(len(path)), regardless of how many were added.
+//
+// A path added with AddPath has priority over any prefix; otherwise the
+// longest matching prefix wins. Adding the same path or prefix again
+// replaces its value.
+//
+// PathTrie is a Matcher that matches if Lookup finds a value for the URL
+// path.
+type PathTrie[T any] struct {
+	root trieNode[T]
+	size int
+}
+
+// trieNode is a node in a path trie, for one byte of the paths.
+type trieNode[T any] struct {
+	children map[byte]*trieNode[T]
+	exact    *T // value for the path ending here
+	prefix   *T // value for the prefix ending here
+}
+
+// AddPath stores a value for a static path.
+func (t *PathTrie[T]) AddPath(path string, value T) {
+	n := t.node(path)
+	if n.exact == nil {
+		t.size++
+	}
+	n.exact = &value
+}
+
+// AddPrefix stores a value for a path prefix.
+func (t *PathTrie[T]) AddPrefix(prefix string, value T) {
+	n := t.node(prefix)
+	if n.prefix == nil {
+		t.size++
+	}
+	n.prefix = &value
+}
+
+// Len returns the number of paths and prefixes stored.
+func (t *PathTrie[T]) Len() int {
+	return t.size
+}
+
+// Lookup returns the value stored for the path, or else for its longest
+// stored prefix. The returned string is the matching path or prefix.
+func (t *PathTrie[T]) Lookup(path string) (value T, matched string, ok bool) {
+	var best *T
+	var bestLen int
+	n := &t.root
+	for i := 0; ; i++ {
+		if n.prefix != nil {
+			best, bestLen = n.prefix, i
+		}
+		if i == len(path) {
+			if n.exact != nil {
+				return *n.exact, path, true
+			}
+			break
+		}
+		if n = n.children[path[i]]; n == nil {
+			break
+		}
+	}
+	if best == nil {
+		return value, "", false
+	}
+	return *best, path[:bestLen], true
+}
+
+func (t *PathTrie[T]) Match(r *http.Request) bool {
+	_, _, ok := t.Lookup(r.URL.Path)
+	return ok
+}
+
+func (t *PathTrie[T]) String() string {
+	return "PathTrie"
+}
+
+// node returns the node for a path, creating it if needed.
+func (t *PathTrie[T]) node(path string) *trieNode[T] {
+	n := &t.root
+	for i := 0; i < len(path); i++ {
+		child := n.children[path[i]]
+		if child == nil {
+			if n.children == nil {
+				n.children = map[byte]*trieNode[T]{}
+			}
+			child = &trieNode[T]{}
+			n.children[path[i]] = child
+		}
+		n = child
+	}
+	return n
+}
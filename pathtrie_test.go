@@ -0,0 +1,49 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPathTrie(t *testing.T) {
+	trie := NewPathTrie[string]()
+	trie.AddPath("/", "home")
+	trie.AddPath("/users", "users")
+	trie.AddPrefix("/users/", "user prefix")
+	trie.AddPrefix("/static/", "static")
+	trie.AddPrefix("/static/img/", "images")
+	trie.AddPath("/static/img/logo.png", "logo")
+	trie.AddPrefix("/static/", "static files")
+	if trie.Len() != 6 {
+		t.Errorf("expected 6 entries, got %d", trie.Len())
+	}
+	tests := []struct {
+		path    string
+		value   string
+		matched string
+		ok      bool
+	}{
+		{"/", "home", "/", true},
+		{"/users", "users", "/users", true},
+		{"/users/42", "user prefix", "/users/", true},
+		{"/users/", "user prefix", "/users/", true},
+		{"/static/css/a.css", "static files", "/static/", true},
+		{"/static/img/a.png", "images", "/static/img/", true},
+		{"/static/img/logo.png", "logo", "/static/img/logo.png", true},
+		{"/user", "", "", false},
+		{"/other", "", "", false},
+	}
+	for _, v := range tests {
+		value, matched, ok := trie.Lookup(v.path)
+		if value != v.value || matched != v.matched || ok != v.ok {
+			t.Errorf("%s: expected %q %q %v, got %q %q %v", v.path, v.value,
+				v.matched, v.ok, value, matched, ok)
+		}
+		r, _ := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		testMatcher(t, "PathTrie", trie, r, v.ok)
+	}
+}
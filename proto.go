@@ -0,0 +1,61 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProtoKey is the variable name used by Proto for the request protocol.
+const ProtoKey = "proto"
+
+// NewProto returns a protocol version matcher, like "HTTP/1.1" or
+// "HTTP/2.0". A version without a minor number, like "HTTP/2", is the same
+// as a zero minor one.
+func NewProto(m []string) Proto {
+	for k, v := range m {
+		v = strings.ToUpper(v)
+		if !strings.Contains(v, ".") {
+			v += ".0"
+		}
+		if major, minor, ok := http.ParseHTTPVersion(v); ok {
+			v = protoString(major, minor)
+		}
+		m[k] = v
+	}
+	return Proto(m)
+}
+
+// Proto matches the protocol version of the request, using r.ProtoMajor and
+// r.ProtoMinor. One of the versions must match.
+type Proto []string
+
+func (m Proto) Match(r *http.Request) bool {
+	proto := protoString(r.ProtoMajor, r.ProtoMinor)
+	for _, v := range m {
+		if v == proto {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Proto) String() string {
+	return "Proto(" + formatList(m) + ")"
+}
+
+// Extract returns the request protocol, like "HTTP/2.0", using ProtoKey.
+func (m Proto) Extract(result *Result, r *http.Request) {
+	result.Values = mergeValues(result.Values, url.Values{ProtoKey: {
+		protoString(r.ProtoMajor, r.ProtoMinor)}})
+}
+
+// protoString formats a protocol version.
+func protoString(major, minor int) string {
+	return fmt.Sprintf("HTTP/%d.%d", major, minor)
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProto(t *testing.T) {
+	const name = "Proto"
+	tests := []struct {
+		matcher Proto
+		major   int
+		minor   int
+		expect  bool
+	}{
+		{NewProto([]string{"HTTP/2"}), 2, 0, true},
+		{NewProto([]string{"http/2.0"}), 2, 0, true},
+		{NewProto([]string{"HTTP/2"}), 1, 1, false},
+		{NewProto([]string{"HTTP/1.0", "HTTP/1.1"}), 1, 1, true},
+		{NewProto([]string{"HTTP/1.0", "HTTP/1.1"}), 2, 0, false},
+		{NewProto([]string{"SPDY/3"}), 1, 1, false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.ProtoMajor, r.ProtoMinor = v.major, v.minor
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+	r, _ := http.NewRequest("GET", "http://domain.com/", nil)
+	r.ProtoMajor, r.ProtoMinor = 2, 0
+	result := &Result{}
+	NewProto([]string{"HTTP/2"}).Extract(result, r)
+	if expect := (url.Values{ProtoKey: {"HTTP/2.0"}}); !equalValues(result.Values, expect) {
+		t.Errorf("expected values %v, got %v", expect, result.Values)
+	}
+}
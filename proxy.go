@@ -0,0 +1,85 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// NewProxyTarget returns a proxy target for an upstream base URL, like
+// "http://users.internal:8080/v2", building the outbound path with b.
+func NewProxyTarget(upstream string, b Builder) (*ProxyTarget, error) {
+	base, err := NewBaseURL(upstream, b)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyTarget{BaseURL: *base}, nil
+}
+
+// ProxyTarget builds the outbound URL of a reverse proxy from the variables
+// extracted by the inbound route: the builder builds the path, which is
+// prepended with the upstream path, and the upstream scheme and host are
+// used. For example, to proxy "/api/users/{id}" to
+// "http://users.internal/v2/accounts/{id}":
+//
+//	target, err := reverse.NewProxyTarget("http://users.internal/v2",
+//		reverse.MustNewGorillaPath("/accounts/{id}", false))
+//	// ...
+//	router.Handle("users", target.Handler(),
+//		reverse.MustNewGorillaPath("/api/users/{id}", false))
+//
+// The variables are read from the request context, where Router, RouteList
+// and MatchHandler store them.
+type ProxyTarget struct {
+	BaseURL
+	// PreserveHost keeps the inbound Host header instead of using the
+	// upstream host.
+	PreserveHost bool
+}
+
+func (t *ProxyTarget) String() string {
+	base := url.URL{Scheme: t.Scheme, Host: t.Host, Path: t.Path}
+	return fmt.Sprintf("ProxyTarget(%q, %v)", base.String(), t.Builder)
+}
+
+// Rewrite sets the URL of an outbound request, using the variables stored
+// in its context. The inbound query is kept, joined with the built one.
+func (t *ProxyTarget) Rewrite(r *http.Request) error {
+	u, err := t.URL(Vars(r))
+	if err != nil {
+		return err
+	}
+	u.Scheme, u.Host = t.Scheme, t.Host
+	switch {
+	case u.RawQuery == "":
+		u.RawQuery = r.URL.RawQuery
+	case r.URL.RawQuery != "":
+		u.RawQuery = u.RawQuery + "&" + r.URL.RawQuery
+	}
+	r.URL = u
+	if !t.PreserveHost {
+		r.Host = ""
+	}
+	return nil
+}
+
+// Director returns a function to be used as httputil.ReverseProxy.Director,
+// calling Rewrite. If building the URL fails, its host is cleared so that
+// the proxy replies with 502 (http.StatusBadGateway).
+func (t *ProxyTarget) Director() func(*http.Request) {
+	return func(r *http.Request) {
+		if err := t.Rewrite(r); err != nil {
+			r.URL.Host = ""
+		}
+	}
+}
+
+// Handler returns a reverse proxy handler to the target.
+func (t *ProxyTarget) Handler() http.Handler {
+	return &httputil.ReverseProxy{Director: t.Director()}
+}
@@ -0,0 +1,78 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestProxyTarget(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + " " + r.URL.RequestURI()))
+	}))
+	defer upstream.Close()
+	target, err := NewProxyTarget(upstream.URL+"/v2",
+		MustNewGorillaPath("/accounts/{id:[0-9]+}", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter()
+	router.Handle("users", target.Handler(), MustNewGorillaPath("/api/users/{id}", false))
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/api/users/1", http.StatusOK, "/v2/accounts/1"},
+		{"/api/users/1?q=a", http.StatusOK, "/v2/accounts/1?q=a"},
+		{"/api/users/x", http.StatusBadGateway, ""},
+	}
+	host := strings.TrimPrefix(upstream.URL, "http://")
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != v.code {
+			t.Errorf("%s: expected %d, got %d", v.path, v.code, w.Code)
+			continue
+		}
+		if v.body != "" && w.Body.String() != host+" "+v.body {
+			t.Errorf("%s: expected %q, got %q", v.path, host+" "+v.body, w.Body.String())
+		}
+	}
+}
+
+func TestProxyTargetRewrite(t *testing.T) {
+	target, err := NewProxyTarget("https://users.internal/v2", NewURLBuilder(
+		MustNewGorillaPath("/accounts/{id}", false),
+		MustNewGorillaQuery("fields", "{fields}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.PreserveHost = true
+	r, err := http.NewRequest("GET", "http://domain.com/users/1?q=a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = SetVars(r, url.Values{"id": {"1"}, "fields": {"name"}})
+	if err := target.Rewrite(r); err != nil {
+		t.Fatal(err)
+	}
+	expected := "https://users.internal/v2/accounts/1?fields=name&q=a"
+	if r.URL.String() != expected || r.Host != "domain.com" {
+		t.Errorf("expected %q with host %q, got %q with host %q", expected,
+			"domain.com", r.URL, r.Host)
+	}
+	if _, err := NewProxyTarget("/v2", MustNewGorillaPath("/", false)); err == nil {
+		t.Errorf("expected error for relative upstream")
+	}
+}
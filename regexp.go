@@ -5,31 +5,60 @@
 package reverse
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 // RegexpHost -----------------------------------------------------------------
 
 // NewRegexpHost returns a regexp matcher for the given URL host pattern.
-func NewRegexpHost(pattern string) (*RegexpHost, error) {
-	r, err := CompileRegexp(pattern)
+//
+// The pattern can use a registered syntax; see WithSyntax.
+func NewRegexpHost(pattern string, opts ...Option) (*RegexpHost, error) {
+	o := newOptions(opts)
+	pattern, err := o.compileSyntax(pattern, true)
 	if err != nil {
 		return nil, err
 	}
-	return &RegexpHost{*r}, nil
+	r, err := o.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpHost{Regexp: *r, opts: o}, nil
+}
+
+// MustNewRegexpHost is like NewRegexpHost but panics if the pattern can't be
+// compiled.
+func MustNewRegexpHost(pattern string, opts ...Option) *RegexpHost {
+	m, err := NewRegexpHost(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewRegexpHost(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
 }
 
 // RegexpHost matches the URL host against a regular expression.
 // The outermost capturing groups are extracted and the host can be reverted.
 type RegexpHost struct {
 	Regexp
+	opts options
 }
 
 func (m *RegexpHost) Match(r *http.Request) bool {
 	return m.MatchString(getHost(r))
 }
 
+func (m *RegexpHost) MatchInfo(info *RequestInfo) bool {
+	return m.MatchString(info.Host())
+}
+
+func (m *RegexpHost) String() string {
+	return fmt.Sprintf("RegexpHost(%q)", m.compiled.String())
+}
+
 // Extract returns positional and named variables extracted from the URL host.
 func (m *RegexpHost) Extract(result *Result, r *http.Request) {
 	result.Values = mergeValues(result.Values, m.Values(getHost(r)))
@@ -51,27 +80,50 @@ func (m *RegexpHost) Build(u *url.URL, values url.Values) error {
 // RegexpPath -----------------------------------------------------------------
 
 // NewRegexpPath returns a regexp matcher for the given URL path pattern.
-func NewRegexpPath(pattern string) (*RegexpPath, error) {
-	r, err := CompileRegexp(pattern)
+//
+// The pattern can use a registered syntax; see WithSyntax.
+func NewRegexpPath(pattern string, opts ...Option) (*RegexpPath, error) {
+	o := newOptions(opts)
+	pattern, err := o.compileSyntax(pattern, false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := o.compile(pattern)
 	if err != nil {
 		return nil, err
 	}
-	return &RegexpPath{*r}, nil
+	return &RegexpPath{Regexp: *r, opts: o}, nil
+}
+
+// MustNewRegexpPath is like NewRegexpPath but panics if the pattern can't be
+// compiled.
+func MustNewRegexpPath(pattern string, opts ...Option) *RegexpPath {
+	m, err := NewRegexpPath(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewRegexpPath(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
 }
 
 // RegexpPath matches the URL path against a regular expression.
 // The outermost capturing groups are extracted and the path can be reverted.
 type RegexpPath struct {
 	Regexp
+	opts options
 }
 
 func (m *RegexpPath) Match(r *http.Request) bool {
-	return m.MatchString(r.URL.Path)
+	return m.MatchString(m.opts.path(r))
+}
+
+func (m *RegexpPath) String() string {
+	return fmt.Sprintf("RegexpPath(%q)", m.compiled.String())
 }
 
 // Extract returns positional and named variables extracted from the URL path.
 func (m *RegexpPath) Extract(result *Result, r *http.Request) {
-	result.Values = mergeValues(result.Values, m.Values(r.URL.Path))
+	result.Values = mergeValues(result.Values, m.Values(m.opts.path(r)))
 }
 
 // Build builds the URL path using the given positional and named variables,
@@ -79,7 +131,221 @@ func (m *RegexpPath) Extract(result *Result, r *http.Request) {
 func (m *RegexpPath) Build(u *url.URL, values url.Values) error {
 	path, err := m.RevertValid(values)
 	if err == nil {
-		u.Path = path
+		err = m.opts.setPath(u, path)
+	}
+	return err
+}
+
+// RegexpQuery ----------------------------------------------------------------
+
+// NewRegexpQuery returns a regexp matcher for the values of the given URL
+// query parameter.
+//
+// Query values are already unescaped when matched and escaped when built,
+// so WithEscaping is rejected.
+func NewRegexpQuery(key, pattern string, opts ...Option) (*RegexpQuery, error) {
+	o := newOptions(opts)
+	if err := o.checkQuery(); err != nil {
+		return nil, err
+	}
+	r, err := o.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpQuery{Regexp: *r, key: key, opts: o}, nil
+}
+
+// MustNewRegexpQuery is like NewRegexpQuery but panics if the pattern can't
+// be compiled.
+func MustNewRegexpQuery(key, pattern string, opts ...Option) *RegexpQuery {
+	m, err := NewRegexpQuery(key, pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewRegexpQuery(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// RegexpQuery matches a URL query parameter against a regular expression.
+// One of the parameter values must match. The outermost capturing groups are
+// extracted and the parameter can be reverted.
+type RegexpQuery struct {
+	Regexp
+	key  string
+	opts options
+}
+
+// Key returns the query parameter name.
+func (m *RegexpQuery) Key() string {
+	return m.key
+}
+
+func (m *RegexpQuery) Match(r *http.Request) bool {
+	_, ok := m.value(r.URL.Query())
+	return ok
+}
+
+func (m *RegexpQuery) MatchInfo(info *RequestInfo) bool {
+	_, ok := m.value(info.Query())
+	return ok
+}
+
+func (m *RegexpQuery) String() string {
+	return fmt.Sprintf("RegexpQuery(%q, %q)", m.key, m.compiled.String())
+}
+
+// Extract returns positional and named variables extracted from the first
+// matching value of the query parameter.
+func (m *RegexpQuery) Extract(result *Result, r *http.Request) {
+	if v, ok := m.value(r.URL.Query()); ok {
+		result.Values = mergeValues(result.Values, m.Values(v))
+	}
+}
+
+// Build builds the query parameter using the given positional and named
+// variables, and sets it in the given URL query.
+func (m *RegexpQuery) Build(u *url.URL, values url.Values) error {
+	v, err := m.RevertValid(values)
+	if err == nil {
+		setQuery(u, m.key, v)
 	}
 	return err
 }
+
+// value returns the first matching value of the query parameter.
+func (m *RegexpQuery) value(query url.Values) (string, bool) {
+	for _, v := range query[m.key] {
+		if m.MatchString(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// RegexpHeader ---------------------------------------------------------------
+
+// NewRegexpHeader returns a regexp matcher for the values of the given
+// request header. The key is converted to the canonical form.
+func NewRegexpHeader(key, pattern string, opts ...Option) (*RegexpHeader, error) {
+	o := newOptions(opts)
+	r, err := o.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpHeader{Regexp: *r, key: http.CanonicalHeaderKey(key),
+		opts: o}, nil
+}
+
+// MustNewRegexpHeader is like NewRegexpHeader but panics if the pattern
+// can't be compiled.
+func MustNewRegexpHeader(key, pattern string, opts ...Option) *RegexpHeader {
+	m, err := NewRegexpHeader(key, pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewRegexpHeader(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// RegexpHeader matches a request header against a regular expression.
+// One of the header values must match. The outermost capturing groups are
+// extracted; for example, to get the version from an Accept header:
+//
+//	m := reverse.MustNewRegexpHeader("Accept",
+//		`^application/vnd\.api\.v(?P<version>\d+)\+json$`)
+type RegexpHeader struct {
+	Regexp
+	key  string
+	opts options
+}
+
+// Key returns the header name, in canonical form.
+func (m *RegexpHeader) Key() string {
+	return m.key
+}
+
+func (m *RegexpHeader) Match(r *http.Request) bool {
+	_, ok := m.value(r)
+	return ok
+}
+
+func (m *RegexpHeader) String() string {
+	return fmt.Sprintf("RegexpHeader(%q, %q)", m.key, m.compiled.String())
+}
+
+// Extract returns positional and named variables extracted from the first
+// matching value of the header.
+func (m *RegexpHeader) Extract(result *Result, r *http.Request) {
+	if v, ok := m.value(r); ok {
+		result.Values = mergeValues(result.Values, m.Values(v))
+	}
+}
+
+// value returns the first matching value of the header.
+func (m *RegexpHeader) value(r *http.Request) (string, bool) {
+	for _, v := range r.Header[m.key] {
+		if m.MatchString(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// RegexpAttr -----------------------------------------------------------------
+
+// AttrFunc returns an attribute of a request, like a header value or the
+// referer, to be matched by a RegexpAttr.
+type AttrFunc func(*http.Request) string
+
+// NewRegexpAttr returns a regexp matcher for a request attribute returned
+// by the given function. The name is only used to describe the matcher:
+//
+//	m, err := reverse.NewRegexpAttr("referer", (*http.Request).Referer,
+//		`^https://(?P<site>[^/]+)/`)
+func NewRegexpAttr(name string, attr AttrFunc, pattern string, opts ...Option) (*RegexpAttr, error) {
+	r, err := newOptions(opts).compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexpAttr{Regexp: *r, name: name, attr: attr}, nil
+}
+
+// MustNewRegexpAttr is like NewRegexpAttr but panics if the pattern can't be
+// compiled.
+func MustNewRegexpAttr(name string, attr AttrFunc, pattern string, opts ...Option) *RegexpAttr {
+	m, err := NewRegexpAttr(name, attr, pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewRegexpAttr(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// RegexpAttr matches a request attribute against a regular expression.
+// The outermost capturing groups are extracted.
+type RegexpAttr struct {
+	Regexp
+	name string
+	attr AttrFunc
+}
+
+// Name returns the attribute name.
+func (m *RegexpAttr) Name() string {
+	return m.name
+}
+
+func (m *RegexpAttr) Match(r *http.Request) bool {
+	return m.MatchString(m.attr(r))
+}
+
+func (m *RegexpAttr) String() string {
+	return fmt.Sprintf("RegexpAttr(%q, %q)", m.name, m.compiled.String())
+}
+
+// Extract returns positional and named variables extracted from the
+// attribute.
+func (m *RegexpAttr) Extract(result *Result, r *http.Request) {
+	if v := m.attr(r); m.MatchString(v) {
+		result.Values = mergeValues(result.Values, m.Values(v))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// NewRegexpSet returns a set of the given regexps, combined into a single
+// one.
+func NewRegexpSet(regexps ...*Regexp) (*RegexpSet, error) {
+	s := &RegexpSet{regexps: regexps, groups: make([]int, len(regexps))}
+	parts := make([]string, len(regexps))
+	group := 1
+	for k, v := range regexps {
+		// Each alternative can match anywhere, like the regexp alone, and is
+		// wrapped in a group to tell which one matched.
+		parts[k] = "(?s:.*?)(" + v.compiled.String() + ")"
+		s.groups[k] = group
+		group += 1 + v.compiled.NumSubexp()
+	}
+	combined, err := regexp.Compile("^(?:" + strings.Join(parts, "|") + ")")
+	if err != nil {
+		return nil, err
+	}
+	s.combined = combined
+	return s, nil
+}
+
+// RegexpSet finds which of many regexps matches a string with a single
+// combined regexp, which is faster than trying them one by one; for example,
+// to dispatch a path to one of many RegexpPath matchers.
+type RegexpSet struct {
+	combined *regexp.Regexp
+	regexps  []*Regexp
+	groups   []int // index of the group wrapping each regexp
+}
+
+// Len returns the number of regexps in the set.
+func (s *RegexpSet) Len() int {
+	return len(s.regexps)
+}
+
+// Index returns the index of the first regexp that matches the string, or
+// -1 if none does.
+func (s *RegexpSet) Index(str string) int {
+	m := s.combined.FindStringSubmatchIndex(str)
+	if m == nil {
+		return -1
+	}
+	for k, group := range s.groups {
+		if m[2*group] != -1 {
+			return k
+		}
+	}
+	return -1
+}
+
+// Values returns the index of the first regexp that matches the string,
+// and the variables it extracts. The index is -1 if none matches.
+func (s *RegexpSet) Values(str string) (int, url.Values) {
+	i := s.Index(str)
+	if i == -1 {
+		return -1, nil
+	}
+	return i, s.regexps[i].Values(str)
+}
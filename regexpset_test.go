@@ -0,0 +1,45 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRegexpSet(t *testing.T) {
+	set, err := NewRegexpSet(
+		MustCompileRegexp(`^/users/(?P<id>\d+)$`),
+		MustCompileRegexp(`^/users/(?P<name>[a-z]+)$`),
+		MustCompileRegexp(`(?i)^/ABOUT$`),
+		MustCompileRegexp(`/(\d+)$`),
+		MustCompileRegexp(`^/users/(?P<name>.*)$`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Len() != 5 {
+		t.Errorf("expected 5 regexps, got %d", set.Len())
+	}
+	tests := []struct {
+		s      string
+		index  int
+		values url.Values
+	}{
+		{"/users/42", 0, url.Values{"id": {"42"}}},
+		{"/users/bob", 1, url.Values{"name": {"bob"}}},
+		{"/about", 2, nil},
+		{"/other/7", 3, url.Values{"": {"7"}}},
+		{"/users/Bob", 4, url.Values{"name": {"Bob"}}},
+		{"/other", -1, nil},
+	}
+	for _, v := range tests {
+		index, values := set.Values(v.s)
+		if index != v.index || !equalValues(values, v.values) {
+			t.Errorf("%s: expected %d %v, got %d %v", v.s, v.index, v.values,
+				index, values)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// NewRemoteAddr returns a matcher for client addresses in the given CIDR
+// ranges, like "10.0.0.0/8", or single addresses, like "127.0.0.1".
+func NewRemoteAddr(ranges ...string) (*RemoteAddr, error) {
+	m := &RemoteAddr{ranges: ranges}
+	for _, v := range ranges {
+		var prefix netip.Prefix
+		var err error
+		if strings.Contains(v, "/") {
+			prefix, err = netip.ParsePrefix(v)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(v)
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.prefixes = append(m.prefixes, prefix.Masked())
+	}
+	return m, nil
+}
+
+// MustNewRemoteAddr is like NewRemoteAddr but panics if a range can't be
+// parsed.
+func MustNewRemoteAddr(ranges ...string) *RemoteAddr {
+	m, err := NewRemoteAddr(ranges...)
+	if err != nil {
+		panic(`reverse: NewRemoteAddr(` + formatList(ranges) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// RemoteAddr matches the client address, from r.RemoteAddr, against a list
+// of CIDR ranges. One of the ranges must contain it.
+//
+// When TrustProxyHeaders is set, the X-Real-IP header or else the first
+// address in X-Forwarded-For is used instead, if present. Only set it behind
+// a proxy that overwrites those headers, because clients can forge them.
+type RemoteAddr struct {
+	TrustProxyHeaders bool
+	ranges            []string
+	prefixes          []netip.Prefix
+}
+
+func (m *RemoteAddr) Match(r *http.Request) bool {
+	addr, ok := m.addr(r)
+	if !ok {
+		return false
+	}
+	for _, v := range m.prefixes {
+		if v.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RemoteAddr) String() string {
+	return "RemoteAddr(" + formatList(m.ranges) + ", " +
+		strconv.FormatBool(m.TrustProxyHeaders) + ")"
+}
+
+// addr returns the client address for the request.
+func (m *RemoteAddr) addr(r *http.Request) (netip.Addr, bool) {
+	s := r.RemoteAddr
+	if m.TrustProxyHeaders {
+		if v := r.Header.Get("X-Real-IP"); v != "" {
+			s = v
+		} else if v := r.Header.Get("X-Forwarded-For"); v != "" {
+			s = strings.TrimSpace(strings.Split(v, ",")[0])
+		}
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return addr, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,48 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	const name = "RemoteAddr"
+	matcher := MustNewRemoteAddr("10.0.0.0/8", "192.168.1.0/24", "::1")
+	trusted := MustNewRemoteAddr("10.0.0.0/8")
+	trusted.TrustProxyHeaders = true
+	tests := []struct {
+		matcher    *RemoteAddr
+		remoteAddr string
+		header     http.Header
+		expect     bool
+	}{
+		{matcher, "10.1.2.3:1234", nil, true},
+		{matcher, "192.168.1.10:80", nil, true},
+		{matcher, "192.168.2.10:80", nil, false},
+		{matcher, "[::1]:8080", nil, true},
+		{matcher, "[::ffff:10.0.0.1]:8080", nil, true},
+		{matcher, "invalid", nil, false},
+		{matcher, "8.8.8.8:1", http.Header{"X-Real-Ip": {"10.0.0.1"}}, false},
+		{trusted, "8.8.8.8:1", http.Header{"X-Real-Ip": {"10.0.0.1"}}, true},
+		{trusted, "8.8.8.8:1", http.Header{"X-Forwarded-For": {"10.0.0.1, 8.8.4.4"}}, true},
+		{trusted, "10.0.0.1:1", http.Header{"X-Forwarded-For": {"8.8.4.4"}}, false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RemoteAddr = v.remoteAddr
+		if v.header != nil {
+			r.Header = v.header
+		}
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+	if _, err := NewRemoteAddr("10.0.0.0/33"); err == nil {
+		t.Errorf("expected error for invalid range")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// NewRequestInfo returns the matching information for a request.
+func NewRequestInfo(r *http.Request) *RequestInfo {
+	return &RequestInfo{Request: r}
+}
+
+// RequestInfo caches values derived from a request, like the parsed URL
+// query, so that they are computed once when many matchers are evaluated
+// for the same request. Header matchers need no caching: their keys are
+// converted to the canonical form when they are created.
+//
+// It is not safe for concurrent use.
+type RequestInfo struct {
+	Request  *http.Request
+	query    url.Values
+	host     string
+	hostPort string
+}
+
+// Query returns the parsed URL query. It must not be modified.
+func (i *RequestInfo) Query() url.Values {
+	if i.query == nil {
+		i.query = i.Request.URL.Query()
+	}
+	return i.query
+}
+
+// Host returns the request host, without the port.
+func (i *RequestInfo) Host() string {
+	if i.host == "" {
+		i.host = getHost(i.Request)
+	}
+	return i.host
+}
+
+// HostPort returns the request host and port, joined, using the default
+// port for the scheme if the request has none.
+func (i *RequestInfo) HostPort() string {
+	if i.hostPort == "" {
+		i.hostPort = hostPort(i.Request)
+	}
+	return i.hostPort
+}
+
+// InfoMatcher is a Matcher that can also match using the cached request
+// information.
+type InfoMatcher interface {
+	Matcher
+	MatchInfo(info *RequestInfo) bool
+}
+
+// MatchInfo matches the request using the cached information if the matcher
+// is an InfoMatcher, or calling Match otherwise.
+func MatchInfo(m Matcher, info *RequestInfo) bool {
+	if v, ok := m.(InfoMatcher); ok {
+		return v.MatchInfo(info)
+	}
+	return m.Match(info.Request)
+}
@@ -0,0 +1,63 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRequestInfo(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://www.domain.com/a?page=2&q=go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := NewRequestInfo(r)
+	if v := info.Query().Get("page"); v != "2" {
+		t.Errorf("expected page 2, got %q", v)
+	}
+	if v := info.Host(); v != "www.domain.com" {
+		t.Errorf("expected www.domain.com, got %q", v)
+	}
+	if v := info.HostPort(); v != "www.domain.com:80" {
+		t.Errorf("expected www.domain.com:80, got %q", v)
+	}
+	// The parsed query is cached.
+	r.URL.RawQuery = ""
+	if v := info.Query().Get("page"); v != "2" {
+		t.Errorf("expected the cached query, got page %q", v)
+	}
+}
+
+func TestMatchInfo(t *testing.T) {
+	matchers := []Matcher{
+		NewHost("www.domain.com"),
+		NewQuery(map[string]string{"q": "go"}),
+		MustNewRegexpHost(`^www\.`),
+		MustNewGorillaQuery("page", "{page:[0-9]+}"),
+		MustNewGorillaHost("{sub}.domain.com:80"),
+		MustNewHostPort("{host}:80"),
+		NewNot(NewQuery(map[string]string{"q": "rust"})),
+		NewOne([]Matcher{NewPath("/b"), NewQuery(map[string]string{"page": ""})}),
+		NewRouteSpec().Host("{sub}.domain.com").Queries("q", "{q}"),
+		NewAll([]Matcher{NewMethod([]string{"GET"}), NewQuery(map[string]string{"page": "3"})}),
+	}
+	r, err := http.NewRequest("GET", "http://www.domain.com/a?page=2&q=go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := NewRequestInfo(r)
+	for _, m := range matchers {
+		if _, ok := m.(InfoMatcher); !ok {
+			t.Errorf("%v: expected an InfoMatcher", m)
+		}
+		if expect, got := m.Match(r), MatchInfo(m, info); expect != got {
+			t.Errorf("%v: expected %v, got %v", m, expect, got)
+		}
+	}
+	if !MatchInfo(NewMethod([]string{"GET"}), info) {
+		t.Errorf("expected MatchInfo to fall back to Match")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+)
+
+// Resolve returns the name of the first route whose URL matchers match the
+// given URL, and the variables they extract. It can tell which route built
+// a link, for example in analytics pipelines or in tests.
+//
+// Only the URL is matched: matchers for other parts of a request, like
+// Method, Header or ContentType, are ignored. Relative URLs don't match
+// routes that require a host, and the slash policy is not applied.
+func (r *Router) Resolve(u *url.URL) (string, url.Values, bool) {
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Host:       u.Host,
+		Header:     http.Header{},
+		RequestURI: u.RequestURI(),
+	}
+	if u.Scheme == "https" {
+		req.TLS = &tls.ConnectionState{}
+	}
+	for _, route := range r.routes {
+		if matchURL(route.Matcher, req) {
+			result := &Result{}
+			route.Extract(result, req)
+			return route.Name, result.Values, true
+		}
+	}
+	return "", nil, false
+}
+
+// matchURL matches the request, considering that the matchers that don't
+// depend on the URL always match.
+func matchURL(m Matcher, r *http.Request) bool {
+	if !usesURL(m) {
+		return true
+	}
+	switch v := m.(type) {
+	case All:
+		for _, sub := range v {
+			if !matchURL(sub, r) {
+				return false
+			}
+		}
+		return true
+	case One:
+		for _, sub := range v {
+			if matchURL(sub, r) {
+				return true
+			}
+		}
+		return false
+	case Not:
+		return !matchURL(v.Matcher, r)
+	case *Route:
+		return matchURL(v.Matcher, r)
+	case *RouteSpec:
+		return matchURL(v.Matcher(), r)
+	case *Hooked:
+		return matchURL(v.Unwrap(), r)
+	}
+	return m.Match(r)
+}
+
+// usesURL returns whether a matcher, or one it is composed of, may depend on
+// the request URL.
+func usesURL(m Matcher) bool {
+	uses := false
+	walkMatchers(m, func(m Matcher) {
+		switch m.(type) {
+		case All, One, Not, *Route, *RouteSpec, *Hooked:
+		case Method, SmartMethod, Header, *RegexpHeader, ContentType, Accept,
+			ContentLength, Proto, Upgrade, *RemoteAddr, *TimeWindow:
+		default:
+			uses = true
+		}
+	})
+	return uses
+}
@@ -0,0 +1,53 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRouterResolve(t *testing.T) {
+	router := NewRouter()
+	router.Handle("create", http.NotFoundHandler(), All{Method{"POST"},
+		MustNewGorillaPath("/users/", false)})
+	router.Handle("user", http.NotFoundHandler(), All{
+		MustNewGorillaHost("{tenant}.example.com"),
+		MustNewGorillaPath("/users/{id:[0-9]+}", false),
+		Header{"X-Requested-With": "XMLHttpRequest"}})
+	router.Handle("public", http.NotFoundHandler(), All{
+		Not{PathPrefix("/admin/")}, MustNewGorillaPathPrefix("/{section}/")})
+	tests := []struct {
+		url    string
+		name   string
+		values url.Values
+		ok     bool
+	}{
+		{"/users/", "create", url.Values{}, true},
+		{"https://acme.example.com/users/1", "user", url.Values{"tenant": {"acme"}, "id": {"1"}}, true},
+		{"/users/1", "public", url.Values{"section": {"users"}}, true},
+		{"/admin/users", "", nil, false},
+		{"/", "", nil, false},
+	}
+	for _, v := range tests {
+		u, err := url.Parse(v.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, values, ok := router.Resolve(u)
+		if name != v.name || ok != v.ok || !equalValues(v.values, values) {
+			t.Errorf("%s: expected %q %v %v, got %q %v %v", v.url, v.name, v.values,
+				v.ok, name, values, ok)
+		}
+	}
+	u, err := router.URL("user", url.Values{"tenant": {"acme"}, "id": {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _, _ := router.Resolve(u); name != "user" {
+		t.Errorf("%s: expected %q, got %q", u, "user", name)
+	}
+}
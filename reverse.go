@@ -10,6 +10,10 @@ import (
 	"net/url"
 	"regexp"
 	"regexp/syntax"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
 )
 
 // Regexp stores a regular expression that can be "reverted" or "built":
@@ -19,30 +23,197 @@ type Regexp struct {
 	template string         // reverse template
 	groups   []string       // order of positional and named capturing groups;
 	// names for named and empty strings for positional
-	indices []int // indices of the outermost groups
+	indices  []int             // indices of the outermost groups
+	optional []bool            // whether each outermost group is optional
+	sections []section         // optional sections of the template
+	repeats  []repeat          // repeated sections of the template
+	affixes  []affix           // literals kept from the outermost groups
+	patterns *groupPatterns    // patterns of the outermost groups' values
+	escaping Escaping          // how values are escaped
+	defaults map[string]string // default values for named groups
+	vars     *VarRegistry      // transformers for named values
 }
 
+// Escaping defines how variable values are escaped when reverting a regexp,
+// and unescaped when extracted.
+type Escaping int
+
+const (
+	// NoEscaping uses values as they are. This is the default.
+	NoEscaping Escaping = iota
+	// PathEscaping escapes values using url.PathEscape, so that a value
+	// like "a/b" becomes "a%2Fb".
+	PathEscaping
+	// QueryEscaping escapes values using url.QueryEscape.
+	QueryEscaping
+)
+
+// escape escapes a value.
+func (e Escaping) escape(s string) string {
+	switch e {
+	case PathEscaping:
+		return url.PathEscape(s)
+	case QueryEscaping:
+		return url.QueryEscape(s)
+	}
+	return s
+}
+
+// unescape unescapes a value. Invalid escapes are left as they are.
+func (e Escaping) unescape(s string) string {
+	var unescaped string
+	var err error
+	switch e {
+	case PathEscaping:
+		unescaped, err = url.PathUnescape(s)
+	case QueryEscaping:
+		unescaped, err = url.QueryUnescape(s)
+	default:
+		return s
+	}
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
+
+// DuplicatePolicy defines how outermost capturing groups sharing the same
+// name are handled.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesAllowed keeps duplicated names as they are: Values() appends
+	// one value per group to the same key, and Revert() consumes the values
+	// for that key in order. This is the default used by CompileRegexp.
+	//
+	// The n-th value of a key always belongs to the n-th group with that
+	// name, from left to right, as reported by Occurrences(); each value is
+	// validated against the pattern of its own group. If one of the groups
+	// is optional and doesn't participate in a match, the values of the
+	// following groups move up, so optional groups should use
+	// DuplicatesIndexed instead.
+	DuplicatesAllowed DuplicatePolicy = iota
+	// DuplicatesRejected makes compilation fail if two outermost groups
+	// share the same name.
+	DuplicatesRejected
+	// DuplicatesIndexed renames duplicated groups using their position among
+	// groups with the same name, so `(?P<foo>\d)(?P<foo>\d)` exposes the keys
+	// "foo[0]" and "foo[1]". Unique names are not changed.
+	DuplicatesIndexed
+)
+
 // CompileRegexp compiles a regular expression pattern and creates a template
 // to revert it.
+//
+// Duplicated group names are allowed; see DuplicatesAllowed. Alternations
+// outside capturing groups, like `/(?:users|people)/`, revert to their first
+// alternative, and an error is returned if it is not a literal.
 func CompileRegexp(pattern string) (*Regexp, error) {
-	compiled, err := regexp.Compile(pattern)
+	return CompileRegexpPolicy(pattern, DuplicatesAllowed)
+}
+
+// MustCompileRegexp is like CompileRegexp but panics if the pattern can't be
+// compiled. It simplifies safe initialization of global variables.
+func MustCompileRegexp(pattern string) *Regexp {
+	r, err := CompileRegexp(pattern)
 	if err != nil {
-		return nil, err
+		panic(`reverse: CompileRegexp(` + strconv.Quote(pattern) + `): ` + err.Error())
+	}
+	return r
+}
+
+// CompileRegexpPolicy is like CompileRegexp but sets how duplicated names
+// in the outermost capturing groups are handled.
+func CompileRegexpPolicy(pattern string, policy DuplicatePolicy) (*Regexp, error) {
+	return compileRegexp(pattern, options{duplicates: policy})
+}
+
+// CompileRegexpWith is like CompileRegexp but accepts options. The options
+// that apply are WithCaseFold, WithDuplicates, WithEscaping,
+// WithGroupLiterals, WithStrictTemplate and WithVarRegistry.
+func CompileRegexpWith(pattern string, opts ...Option) (*Regexp, error) {
+	return compileRegexp(pattern, newOptions(opts))
+}
+
+// CompileRegexpSyntax is like CompileRegexp but parses the pattern with the
+// given flags, like syntax.POSIX, instead of syntax.Perl. The regexp used for
+// matching is compiled from the same parsed pattern, and uses leftmost-longest
+// matching, like regexp.CompilePOSIX, if the flags don't have syntax.PerlX.
+func CompileRegexpSyntax(pattern string, flags syntax.Flags) (*Regexp, error) {
+	return compileRegexp(pattern, options{flags: flags, customFlags: true})
+}
+
+// compileRegexp compiles a regexp using the given options.
+func compileRegexp(pattern string, o options) (*Regexp, error) {
+	if o.cache != nil {
+		r, err := o.cache.compile(pattern, o)
+		if err == nil {
+			r.vars = o.vars
+		}
+		return r, err
 	}
-	re, err := syntax.Parse(pattern, syntax.Perl)
+	source := pattern
+	compiled, re, err := o.parse(pattern)
 	if err != nil {
 		return nil, err
 	}
-	tpl := &template{buffer: new(bytes.Buffer)}
+	tpl := &template{buffer: new(bytes.Buffer), literals: o.groupLiterals,
+		strict: o.strictTemplate, source: source}
 	tpl.write(re)
+	if tpl.err != nil {
+		return nil, tpl.err
+	}
+	if err = tpl.applyPolicy(o.duplicates); err != nil {
+		return nil, err
+	}
 	return &Regexp{
 		compiled: compiled,
 		template: tpl.buffer.String(),
 		groups:   tpl.groups,
 		indices:  tpl.indices,
+		optional: tpl.optional,
+		sections: tpl.sections,
+		repeats:  tpl.repeats,
+		affixes:  tpl.affixes,
+		patterns: &groupPatterns{parsed: tpl.patterns},
+		escaping: o.escaping,
+		vars:     o.vars,
 	}, nil
 }
 
+// parse compiles a pattern for matching and parses it to build a template,
+// using the syntax flags and case folding set in the options.
+func (o options) parse(pattern string) (*regexp.Regexp, *syntax.Regexp, error) {
+	if !o.customFlags {
+		if o.caseFold {
+			pattern = "(?i)" + pattern
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		return compiled, re, err
+	}
+	flags := o.flags
+	if o.caseFold {
+		flags |= syntax.FoldCase
+	}
+	re, err := syntax.Parse(pattern, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The parsed regexp is printed using the Perl syntax.
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if flags&syntax.PerlX == 0 {
+		compiled.Longest()
+	}
+	return compiled, re, nil
+}
+
 // Compiled returns the compiled regular expression to be used for matching.
 func (r *Regexp) Compiled() *regexp.Regexp {
 	return r.compiled
@@ -53,6 +224,37 @@ func (r *Regexp) Template() string {
 	return r.template
 }
 
+// TemplateNamed returns the reverse template for the regexp using named
+// placeholders, like "/users/{id}", which doesn't depend on fmt. Positional
+// groups use their position among the positional groups, like "{0}".
+// Optional sections are kept.
+func (r *Regexp) TemplateNamed() NamedTemplate {
+	var b strings.Builder
+	var group, positional int
+	for i := 0; i < len(r.template); i++ {
+		switch c := r.template[i]; {
+		case c == '%' && r.template[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case c == '%':
+			// A "%s" placeholder.
+			name := r.groups[group]
+			if name == "" {
+				name = strconv.Itoa(positional)
+				positional++
+			}
+			b.WriteString("{" + name + "}")
+			group++
+			i++
+		case c == '{' || c == '}':
+			b.WriteString(string([]byte{c, c}))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return NamedTemplate(b.String())
+}
+
 // Groups returns an ordered list of the outermost capturing groups found in
 // the regexp.
 //
@@ -62,6 +264,22 @@ func (r *Regexp) Groups() []string {
 	return r.groups
 }
 
+// Occurrences returns, for each of the outermost capturing groups in the
+// same order as Groups(), its position among the groups with the same name,
+// starting at zero. Positional groups are counted among positional groups.
+//
+// For `(?P<foo>\d)(?P<bar>\d)(?P<foo>\d)` it returns [0 0 1]: the values
+// of "foo" are, in order, for the first and third groups.
+func (r *Regexp) Occurrences() []int {
+	occurrences := make([]int, len(r.groups))
+	seen := map[string]int{}
+	for k, v := range r.groups {
+		occurrences[k] = seen[v]
+		seen[v]++
+	}
+	return occurrences
+}
+
 // Indices returns the indices of the outermost capturing groups found in
 // the regexp.
 //
@@ -70,6 +288,15 @@ func (r *Regexp) Indices() []int {
 	return r.indices
 }
 
+// Optional returns whether each of the outermost capturing groups is
+// optional, in the same order as Groups().
+//
+// A group is optional when it is under a quantifier that allows zero
+// repetitions, like in `/items(?:/(?P<id>\d+))?`.
+func (r *Regexp) Optional() []bool {
+	return r.optional
+}
+
 // Match returns whether the regexp matches the given string.
 func (r *Regexp) MatchString(s string) bool {
 	return r.compiled.MatchString(s)
@@ -78,41 +305,382 @@ func (r *Regexp) MatchString(s string) bool {
 // Values matches the regexp and returns the results for positional and
 // named groups. Positional values are stored using an empty string as key.
 // If the string doesn't match it returns nil.
+//
+// Values are unescaped according to the escaping set with WithEscaping, and
+// transformed by the registry set with WithVarRegistry.
 func (r *Regexp) Values(s string) url.Values {
-	match := r.compiled.FindStringSubmatch(s)
-	if match != nil {
-		values := url.Values{}
-		for k, v := range r.groups {
-			values.Add(v, match[r.indices[k]])
+	var values url.Values
+	if !r.Visit(s, func(name, value string) bool {
+		if values == nil {
+			values = url.Values{}
 		}
-		return values
+		values.Add(name, value)
+		return true
+	}) {
+		return nil
 	}
-	return nil
+	if values == nil {
+		values = url.Values{}
+	}
+	return values
+}
+
+// Visit matches the regexp and calls fn for each value that Values would
+// return, in the order of Groups() followed by the default values, until fn
+// returns false. It returns whether the string matched.
+//
+// Unlike Values, it doesn't allocate a map, so it can be used when only some
+// of the values are needed:
+//
+//	var id string
+//	re.Visit(path, func(name, value string) bool {
+//		if name == "id" {
+//			id = value
+//			return false
+//		}
+//		return true
+//	})
+func (r *Regexp) Visit(s string, fn func(name, value string) bool) bool {
+	match := r.compiled.FindStringSubmatchIndex(s)
+	if match == nil {
+		return false
+	}
+	for k, name := range r.groups {
+		value, ok := r.groupValue(s, match, k)
+		if ok && !fn(name, value) {
+			return true
+		}
+	}
+	for name, value := range r.defaults {
+		if !r.hasValue(match, name) && !fn(name, value) {
+			return true
+		}
+	}
+	return true
+}
+
+// AppendValues matches the regexp and appends one value for each of the
+// outermost capturing groups to dst, in the same order as Groups(), and
+// returns the extended slice. Groups that didn't participate in the match
+// get their default value, or an empty string. If the string doesn't match
+// it returns dst unchanged.
+func (r *Regexp) AppendValues(dst []string, s string) []string {
+	match := r.compiled.FindStringSubmatchIndex(s)
+	if match == nil {
+		return dst
+	}
+	for k, name := range r.groups {
+		value, ok := r.groupValue(s, match, k)
+		if !ok {
+			value = r.defaults[name]
+		}
+		dst = append(dst, value)
+	}
+	return dst
+}
+
+// groupValue returns the unescaped and transformed value of the outermost
+// group k, or false if it didn't participate in the match.
+func (r *Regexp) groupValue(s string, match []int, k int) (string, bool) {
+	idx := r.indices[k] * 2
+	if match[idx] < 0 {
+		// Optional group that didn't participate in the match.
+		return "", false
+	}
+	value := r.affixes[k].trim(s[match[idx]:match[idx+1]])
+	return r.vars.extract(r.groups[k], r.escaping.unescape(value)), true
+}
+
+// hasValue returns whether a group with the given name participated in the
+// match.
+func (r *Regexp) hasValue(match []int, name string) bool {
+	for k, v := range r.groups {
+		if v == name && match[r.indices[k]*2] >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// extractValues matches the regexp and adds its values to dst, which is
+// allocated if nil and there is a match.
+func (r *Regexp) extractValues(dst url.Values, s string) url.Values {
+	if r.Visit(s, func(name, value string) bool {
+		if dst == nil {
+			dst = url.Values{}
+		}
+		dst[name] = append(dst[name], value)
+		return true
+	}) && dst == nil {
+		dst = url.Values{}
+	}
+	return dst
 }
 
 // Revert builds a string for this regexp using the given values. Positional
 // values use an empty string as key.
 //
+// Optional sections are left out when none of their groups have a value.
+// Repeated groups, like `(?:/(?P<tag>[a-z]+))*`, are written once for each
+// value, within the bounds of their quantifier; note that extracting values
+// only returns the last repetition, as the regexp package does.
+// Missing values use the defaults of Gorilla patterns, if any.
+// Values are transformed by the registry set with WithVarRegistry, and
+// escaped according to the escaping set with WithEscaping.
+//
 // The values are modified in place, and only the unused ones are left.
 func (r *Regexp) Revert(values url.Values) (string, error) {
-	vars := make([]interface{}, len(r.groups))
-	for k, v := range r.groups {
+	if err := r.checkRepeats(values); err != nil {
+		return "", err
+	}
+	tpl, seq := r.layout(values)
+	n := len(r.groups)
+	if seq != nil {
+		n = len(seq)
+	}
+	vars := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		k := i
+		if seq != nil {
+			k = seq[i]
+		}
+		v := r.groups[k]
 		if len(values[v]) == 0 {
+			if def, ok := r.defaults[v]; ok {
+				vars = append(vars, r.escaping.escape(def))
+				continue
+			}
 			return "", fmt.Errorf(
 				"Missing key %q to revert the regexp "+
 					"(expected a total of %d variables)", v, len(r.groups))
 		}
-		vars[k] = values[v][0]
+		vars = append(vars, r.escaping.escape(r.vars.build(v, values[v][0])))
 		values[v] = values[v][1:]
 	}
-	return fmt.Sprintf(r.template, vars...), nil
+	return fmt.Sprintf(tpl, vars...), nil
+}
+
+// RevertArgs is like Revert but takes one value for each of the outermost
+// capturing groups, in the order of Groups().
+func (r *Regexp) RevertArgs(args ...string) (string, error) {
+	if len(args) != len(r.groups) {
+		return "", fmt.Errorf("Expected %d arguments to revert the regexp, "+
+			"got %d", len(r.groups), len(args))
+	}
+	values := url.Values{}
+	for k, v := range r.groups {
+		values.Add(v, args[k])
+	}
+	return r.Revert(values)
+}
+
+// RevertPairs is like Revert but takes a list of key/value pairs, like
+// "id", "42", "slug", "hello". Positional values use an empty key.
+func (r *Regexp) RevertPairs(pairs ...string) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("Number of parameters must be multiple of 2, "+
+			"got %v", pairs)
+	}
+	values := url.Values{}
+	for i := 0; i < len(pairs); i += 2 {
+		values.Add(pairs[i], pairs[i+1])
+	}
+	return r.Revert(values)
+}
+
+// skipSections returns which groups belong to optional sections that have
+// no values, or nil if all sections must be kept.
+func (r *Regexp) skipSections(values url.Values) []bool {
+	var skip []bool
+	for _, sec := range r.sections {
+		missing := true
+		for _, v := range r.groups[sec.first:sec.last] {
+			if _, ok := r.defaults[v]; ok || len(values[v]) != 0 {
+				missing = false
+				break
+			}
+		}
+		if missing {
+			if skip == nil {
+				skip = make([]bool, len(r.groups))
+			}
+			for k := sec.first; k < sec.last; k++ {
+				skip[k] = true
+			}
+		}
+	}
+	return skip
+}
+
+// layout returns the template to revert with the given values, without
+// the optional sections that have no values and with the repeated sections
+// written as needed, and the outermost group of each placeholder, in order.
+// The groups are nil if each one has a single placeholder.
+func (r *Regexp) layout(values url.Values) (string, []int) {
+	skip := r.skipSections(values)
+	if skip == nil && r.repeats == nil {
+		return r.template, nil
+	}
+	var b strings.Builder
+	seq := make([]int, 0, len(r.groups))
+	pos, group := 0, 0
+	secs, reps := r.sections, r.repeats
+	for len(secs) > 0 || len(reps) > 0 {
+		var sec section
+		var count int
+		if len(reps) == 0 || len(secs) > 0 && secs[0].start < reps[0].start {
+			sec, secs = secs[0], secs[1:]
+			if skip == nil || !skip[sec.first] {
+				continue
+			}
+		} else {
+			sec, count = reps[0].section, r.repeatCount(reps[0], values)
+			if reps[0].max >= 0 && count > reps[0].max {
+				count = reps[0].max
+			}
+			reps = reps[1:]
+		}
+		b.WriteString(r.template[pos:sec.start])
+		seq = appendGroups(seq, group, sec.first)
+		for i := 0; i < count; i++ {
+			b.WriteString(r.template[sec.start:sec.end])
+			seq = appendGroups(seq, sec.first, sec.last)
+		}
+		pos, group = sec.end, sec.last
+	}
+	b.WriteString(r.template[pos:])
+	return b.String(), appendGroups(seq, group, len(r.groups))
+}
+
+// appendGroups appends the group indices from first to last to seq.
+func appendGroups(seq []int, first, last int) []int {
+	for k := first; k < last; k++ {
+		seq = append(seq, k)
+	}
+	return seq
+}
+
+// repeatCount returns how many times a repeated section is written: as many
+// as values are given for its groups, besides the ones used by the groups
+// outside repeated sections. Groups with defaults can be written the
+// minimum number of times without values.
+func (r *Regexp) repeatCount(rp repeat, values url.Values) int {
+	count, defaults := -1, true
+	for _, name := range r.groups[rp.first:rp.last] {
+		n := len(values[name])
+		for k, v := range r.groups {
+			if v == name && !r.inRepeat(k) {
+				n--
+			}
+		}
+		if count < 0 || n < count {
+			count = n
+		}
+		if _, ok := r.defaults[name]; !ok {
+			defaults = false
+		}
+	}
+	if count < rp.min && defaults {
+		return rp.min
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
 }
 
-// RevertValid is the same as Revert but it also validates the resulting
-// string matching it against the compiled regexp.
+// inRepeat returns whether the outermost group k is in a repeated section.
+func (r *Regexp) inRepeat(k int) bool {
+	for _, rp := range r.repeats {
+		if k >= rp.first && k < rp.last {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRepeats checks that the number of values given for each repeated
+// section is within the bounds of its quantifier.
+func (r *Regexp) checkRepeats(values url.Values) error {
+	for _, rp := range r.repeats {
+		n := r.repeatCount(rp, values)
+		if n < rp.min {
+			return fmt.Errorf("Expected at least %d values for the repeated "+
+				"group %q, got %d", rp.min, r.groups[rp.first], n)
+		}
+		if rp.max >= 0 && n > rp.max {
+			return fmt.Errorf("Expected at most %d values for the repeated "+
+				"group %q, got %d", rp.max, r.groups[rp.first], n)
+		}
+	}
+	return nil
+}
+
+// ValidateValues checks the given values against the patterns of the groups
+// that would use them when reverting, and returns an error telling the
+// first variable that doesn't match. Missing values are not checked.
+func (r *Regexp) ValidateValues(values url.Values) error {
+	_, seq := r.layout(values)
+	n := len(r.groups)
+	if seq != nil {
+		n = len(seq)
+	}
+	used := map[string]int{}
+	positional := 0
+	for i := 0; i < n; i++ {
+		k := i
+		if seq != nil {
+			k = seq[i]
+		}
+		v := r.groups[k]
+		idx := used[v]
+		used[v]++
+		if v == "" {
+			positional++
+		}
+		if idx >= len(values[v]) {
+			continue
+		}
+		value := r.escaping.escape(r.vars.build(v, values[v][idx]))
+		re, pattern := r.patterns.get(k)
+		if re.MatchString(value) {
+			continue
+		}
+		if v == "" {
+			return fmt.Errorf("Invalid value %q for positional variable %d: "+
+				"it doesn't match %q", value, positional-1, pattern)
+		}
+		if r.repeated(v) {
+			return fmt.Errorf("Invalid value %q for occurrence %d of variable "+
+				"%q: it doesn't match %q", value, idx, v, pattern)
+		}
+		return fmt.Errorf("Invalid value %q for variable %q: it doesn't "+
+			"match %q", value, v, pattern)
+	}
+	return nil
+}
+
+// repeated returns whether more than one of the outermost groups has the
+// given name.
+func (r *Regexp) repeated(name string) bool {
+	n := 0
+	for _, v := range r.groups {
+		if v == name {
+			n++
+		}
+	}
+	return n > 1
+}
+
+// RevertValid is the same as Revert but it also validates the values with
+// ValidateValues, and the resulting string matching it against the
+// compiled regexp.
 //
 // The values are modified in place, and only the unused ones are left.
 func (r *Regexp) RevertValid(values url.Values) (string, error) {
+	if err := r.ValidateValues(values); err != nil {
+		return "", err
+	}
 	reverse, err := r.Revert(values)
 	if err != nil {
 		return "", err
@@ -124,14 +692,143 @@ func (r *Regexp) RevertValid(values url.Values) (string, error) {
 	return reverse, nil
 }
 
+// NamedTemplate is a reverse template with `{name}` placeholders for named
+// groups and `{0}`, `{1}`, etc. for positional ones. Literal braces are
+// doubled, like "{{" and "}}".
+type NamedTemplate string
+
+// Expand builds a string replacing the placeholders with the given values.
+// Positional values use an empty string as key; a name used more than once
+// takes its values in order. Values are not escaped.
+func (t NamedTemplate) Expand(values url.Values) (string, error) {
+	var b strings.Builder
+	used := map[string]int{}
+	s := string(t)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c == '{' || c == '}') && i+1 < len(s) && s[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '}' {
+			return "", fmt.Errorf("Unbalanced braces in template %q", s)
+		}
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("Unbalanced braces in template %q", s)
+		}
+		name := s[i+1 : i+end]
+		i += end
+		key, idx := name, used[name]
+		if n, err := strconv.Atoi(name); err == nil {
+			key, idx = "", n
+		} else {
+			used[name]++
+		}
+		if idx >= len(values[key]) {
+			return "", fmt.Errorf("Missing key %q to expand the template", name)
+		}
+		b.WriteString(values[key][idx])
+	}
+	return b.String(), nil
+}
+
+// groupPatterns compiles the patterns of the outermost groups' values when
+// they are first needed, because formatting parsed regexps is slow.
+type groupPatterns struct {
+	once     sync.Once
+	parsed   []*syntax.Regexp
+	sources  []string
+	compiled []*regexp.Regexp
+}
+
+// get returns the compiled pattern of a group, anchored, and its source.
+func (p *groupPatterns) get(k int) (*regexp.Regexp, string) {
+	p.once.Do(func() {
+		p.sources = make([]string, len(p.parsed))
+		p.compiled = make([]*regexp.Regexp, len(p.parsed))
+		for k, v := range p.parsed {
+			p.sources[k] = v.String()
+			p.compiled[k] = regexp.MustCompile(`^(?:` + p.sources[k] + `)$`)
+		}
+	})
+	return p.compiled[k], p.sources[k]
+}
+
+// section is an optional part of a reverse template.
+type section struct {
+	start, end  int // byte offsets in the template
+	first, last int // range of the outermost groups it contains
+}
+
+// repeat is a section of the template written once for each value of its
+// groups, between min and max times; max is -1 if unbounded.
+type repeat struct {
+	section
+	min, max int
+}
+
+// affix is the number of runes of the literal prefix and suffix kept from
+// a capturing group.
+type affix struct {
+	prefix, suffix int
+}
+
+// trim removes the prefix and suffix from a group value.
+func (a affix) trim(s string) string {
+	if a.prefix == 0 && a.suffix == 0 {
+		return s
+	}
+	runes := []rune(s)
+	if a.prefix+a.suffix > len(runes) {
+		return s
+	}
+	return string(runes[a.prefix : len(runes)-a.suffix])
+}
+
+// TemplateError is returned when a pattern contains a construct that the
+// reverse template can't represent, like a top-level alternation in strict
+// mode; see WithStrictTemplate.
+type TemplateError struct {
+	Pattern   string // the compiled pattern
+	Construct string // what can't be reverted, like "alternation"
+	Expr      string // the construct source, like `(?:a|b)`
+	Offset    int    // byte offset of Expr in Pattern, or -1 if unknown
+}
+
+func (e *TemplateError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("Can't revert %s %q in %q", e.Construct, e.Expr,
+			e.Pattern)
+	}
+	return fmt.Sprintf("Can't revert %s %q at offset %d of %q", e.Construct,
+		e.Expr, e.Offset, e.Pattern)
+}
+
 // template builds a reverse template for a regexp.
 type template struct {
 	buffer *bytes.Buffer
 	groups []string // outermost capturing groups: empty string for
 	// positional or name for named groups
-	indices []int // indices of outermost capturing groups
-	index   int   // current group index
-	level   int   // current capturing group nesting level
+	indices  []int            // indices of outermost capturing groups
+	optional []bool           // whether outermost capturing groups are optional
+	sections []section        // optional sections
+	repeats  []repeat         // repeated sections
+	affixes  []affix          // literals kept from outermost capturing groups
+	patterns []*syntax.Regexp // patterns of the outermost groups' values
+	literals bool             // whether to keep literals from capturing groups
+	level    int              // current capturing group nesting level
+	quest    int              // current optional quantifier nesting level
+	strict   bool             // whether lossy constructs are errors
+	source   string           // pattern, to locate errors
+	pos      int              // source offset after the last literal
+	end      *syntax.Regexp   // end anchor, if already written
+	err      error            // first construct that can't be reverted
 }
 
 // write writes a reverse template to the buffer.
@@ -139,28 +836,298 @@ func (t *template) write(re *syntax.Regexp) {
 	switch re.Op {
 	case syntax.OpLiteral:
 		if t.level == 0 {
-			for _, r := range re.Rune {
-				t.buffer.WriteRune(r)
-				if r == '%' {
-					t.buffer.WriteRune('%')
-				}
-			}
+			t.writeLiteral(re)
+		}
+	case syntax.OpCharClass:
+		// The parser merges alternatives of single characters into a class,
+		// like `v1|v2` into `v[12]`, so its first character is written.
+		if t.level != 0 {
+			return
+		}
+		if t.strict {
+			t.fail(re, "character class")
+		} else if r, ok := firstClassRune(re); ok {
+			t.writeLiteral(&syntax.Regexp{Op: syntax.OpLiteral, Rune: []rune{r}})
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		if t.level == 0 && t.strict {
+			t.fail(re, "wildcard")
+		}
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		if t.level == 0 && t.strict && t.buffer.Len() > 0 {
+			t.fail(re, "anchor")
+		}
+	case syntax.OpEndLine, syntax.OpEndText:
+		if t.level == 0 && t.end == nil {
+			t.end = re
 		}
 	case syntax.OpCapture:
 		t.level++
-		t.index++
 		if t.level == 1 {
+			t.checkEnd()
 			t.groups = append(t.groups, re.Name)
-			t.indices = append(t.indices, t.index)
-			t.buffer.WriteString("%s")
+			t.indices = append(t.indices, re.Cap)
+			t.optional = append(t.optional, t.quest > 0)
+			t.writeGroup(re)
 		}
+		t.level--
+	case syntax.OpConcat:
 		for _, sub := range re.Sub {
 			t.write(sub)
 		}
-		t.level--
+	case syntax.OpQuest, syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		// Quantified expressions are only written if they contain capturing
+		// groups. Those that can repeat are written as repeated sections,
+		// unless they are nested in another quantifier, and then they are
+		// written once.
+		if t.level != 0 {
+			return
+		}
+		if !hasCapture(re) {
+			// Omitting an optional expression is faithful, but not omitting
+			// a required one.
+			if t.strict && (re.Op == syntax.OpPlus ||
+				re.Op == syntax.OpRepeat && re.Min > 0) {
+				t.fail(re, "quantified expression")
+			}
+			return
+		}
+		if min, max := bounds(re); t.quest == 0 && max != 1 {
+			t.writeRepeat(re, min, max)
+			return
+		}
+		if re.Op == syntax.OpPlus || (re.Op == syntax.OpRepeat && re.Min > 0) {
+			t.write(re.Sub[0])
+			return
+		}
+		start, first := t.buffer.Len(), len(t.groups)
+		t.quest++
+		t.write(re.Sub[0])
+		t.quest--
+		if t.quest == 0 && len(t.groups) > first {
+			t.sections = append(t.sections, section{
+				start: start,
+				end:   t.buffer.Len(),
+				first: first,
+				last:  len(t.groups),
+			})
+		}
+	case syntax.OpAlternate:
+		// Only the first alternative is written. Without capturing groups it
+		// must be made of literals, like "foo" in `(?:foo|bar)`; to choose
+		// the alternative when building, use a capturing group instead.
+		// Alternatives of literals may be factored by the parser, so
+		// `(?:v2|v1)` writes "1".
+		if t.level != 0 {
+			return
+		}
+		if t.strict {
+			t.fail(re, "alternation")
+			return
+		}
+		if !hasCapture(re) && !isLiteral(re.Sub[0]) {
+			t.fail(re, "alternation with a non-literal first alternative")
+			return
+		}
+		t.write(re.Sub[0])
+	}
+}
+
+// writeRepeat writes a repeated section for a quantified expression.
+func (t *template) writeRepeat(re *syntax.Regexp, min, max int) {
+	start, first := t.buffer.Len(), len(t.groups)
+	// Nested quantifiers are written once.
+	t.quest++
+	t.write(re.Sub[0])
+	t.quest--
+	for k := first; k < len(t.groups); k++ {
+		t.optional[k] = min == 0
+	}
+	t.repeats = append(t.repeats, repeat{
+		section: section{
+			start: start,
+			end:   t.buffer.Len(),
+			first: first,
+			last:  len(t.groups),
+		},
+		min: min,
+		max: max,
+	})
+}
+
+// bounds returns the minimum and maximum repetitions of a quantifier; the
+// maximum is -1 if unbounded.
+func bounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpQuest:
+		return 0, 1
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	}
+	return re.Min, re.Max
+}
+
+// writeLiteral writes a literal, escaping it for fmt.
+func (t *template) writeLiteral(re *syntax.Regexp) {
+	t.checkEnd()
+	if i := strings.Index(t.source[t.pos:], string(re.Rune)); i != -1 {
+		t.pos += i + len(string(re.Rune))
+	}
+	for _, r := range re.Rune {
+		t.buffer.WriteRune(r)
+		if r == '%' {
+			t.buffer.WriteRune('%')
+		}
+	}
+}
+
+// writeGroup writes the placeholder for an outermost capturing group.
+//
+// If literals are kept, a group made of literals around a single variable
+// part, like `(user-\d+)`, keeps the literals in the template and only the
+// variable part becomes a placeholder. Other groups become a placeholder
+// entirely.
+func (t *template) writeGroup(re *syntax.Regexp) {
+	var a affix
+	parts := []*syntax.Regexp{re.Sub[0]}
+	if re.Sub[0].Op == syntax.OpConcat {
+		parts = re.Sub[0].Sub
+	}
+	start, end := 0, len(parts)
+	for start < end && parts[start].Op == syntax.OpLiteral {
+		start++
+	}
+	for end > start && parts[end-1].Op == syntax.OpLiteral {
+		end--
+	}
+	if !t.literals || end-start != 1 || hasCapture(parts[start]) {
+		t.affixes = append(t.affixes, a)
+		t.patterns = append(t.patterns, re.Sub[0])
+		t.buffer.WriteString("%s")
+		return
+	}
+	for _, part := range parts[:start] {
+		a.prefix += len(part.Rune)
+		t.writeLiteral(part)
+	}
+	t.buffer.WriteString("%s")
+	for _, part := range parts[end:] {
+		a.suffix += len(part.Rune)
+		t.writeLiteral(part)
+	}
+	t.affixes = append(t.affixes, a)
+	t.patterns = append(t.patterns, parts[start])
+}
+
+// checkEnd fails in strict mode if an end anchor was written, because
+// whatever follows it can't be matched.
+func (t *template) checkEnd() {
+	if t.strict && t.end != nil {
+		t.fail(t.end, "anchor")
+	}
+}
+
+// fail records an error for a construct that can't be reverted, unless
+// there is one already.
+func (t *template) fail(re *syntax.Regexp, construct string) {
+	if t.err == nil {
+		expr, offset := t.locate(re)
+		t.err = &TemplateError{Pattern: t.source, Construct: construct,
+			Expr: expr, Offset: offset}
+	}
+}
+
+// locate returns the source of a regexp and its byte offset in the
+// pattern, searching after the last written literal, or -1 if it is not
+// found: the parser normalizes regexps, so only the most common spellings
+// are tried.
+func (t *template) locate(re *syntax.Regexp) (string, int) {
+	var forms []string
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		forms = []string{"^", `\A`}
+	case syntax.OpEndLine, syntax.OpEndText:
+		forms = []string{"$", `\z`}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		forms = []string{"."}
+	case syntax.OpAlternate:
+		forms = []string{"(?:" + re.String() + ")"}
+	}
+	forms = append(forms, re.String())
+	for _, form := range forms {
+		if i := strings.Index(t.source[t.pos:], form); i != -1 {
+			return form, t.pos + i
+		}
+	}
+	return re.String(), -1
+}
+
+// hasCapture returns whether the regexp contains a capturing group.
+func hasCapture(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpCapture {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasCapture(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiteral returns whether the regexp always matches the same string, so
+// that the template can write it.
+func isLiteral(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral, syntax.OpEmptyMatch, syntax.OpBeginLine,
+		syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return true
 	case syntax.OpConcat:
 		for _, sub := range re.Sub {
-			t.write(sub)
+			if !isLiteral(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCharClass:
+		_, ok := firstClassRune(re)
+		return ok
+	case syntax.OpAlternate:
+		return isLiteral(re.Sub[0])
+	}
+	return false
+}
+
+// firstClassRune returns the first character of a class, if it is printable.
+func firstClassRune(re *syntax.Regexp) (rune, bool) {
+	if len(re.Rune) == 0 || !unicode.IsPrint(re.Rune[0]) {
+		return 0, false
+	}
+	return re.Rune[0], true
+}
+
+// applyPolicy checks or renames duplicated names in the outermost groups.
+func (t *template) applyPolicy(policy DuplicatePolicy) error {
+	count := map[string]int{}
+	for _, name := range t.groups {
+		if name != "" {
+			count[name]++
 		}
 	}
+	seen := map[string]int{}
+	for k, name := range t.groups {
+		if count[name] < 2 {
+			continue
+		}
+		switch policy {
+		case DuplicatesRejected:
+			return fmt.Errorf("Duplicate group name %q in the regexp", name)
+		case DuplicatesIndexed:
+			t.groups[k] = fmt.Sprintf("%s[%d]", name, seen[name])
+			seen[name]++
+		}
+	}
+	return nil
 }
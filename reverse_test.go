@@ -5,7 +5,9 @@
 package reverse
 
 import (
+	"net/http"
 	"net/url"
+	"regexp/syntax"
 	"testing"
 )
 
@@ -180,3 +182,483 @@ func stringSliceEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestTemplateNamed(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		template NamedTemplate
+		values   url.Values
+		expect   string
+	}{
+		{`^/users/(?P<id>\d+)$`, "/users/{id}", url.Values{"id": {"42"}}, "/users/42"},
+		{`^/(\w+)/(?P<id>\d+)/(\w+)$`, "/{0}/{id}/{1}",
+			url.Values{"": {"a", "b"}, "id": {"1"}}, "/a/1/b"},
+		{`^100%/\{(?P<x>\w+)\}$`, "100%/{{{x}}}", url.Values{"x": {"y"}}, "100%/{y}"},
+		{`^/(?P<x>\w+)/(?P<x>\w+)$`, "/{x}/{x}", url.Values{"x": {"a", "b"}}, "/a/b"},
+	}
+	for _, v := range tests {
+		r := MustCompileRegexp(v.pattern)
+		tpl := r.TemplateNamed()
+		if tpl != v.template {
+			t.Errorf("%s: expected template %q, got %q", v.pattern, v.template, tpl)
+		}
+		s, err := tpl.Expand(v.values)
+		if err != nil {
+			t.Errorf("%s: %v", v.pattern, err)
+		} else if s != v.expect {
+			t.Errorf("%s: expected %q, got %q", v.pattern, v.expect, s)
+		}
+	}
+	for _, tpl := range []NamedTemplate{"/{id}", "/{0}", "/{id", "/id}"} {
+		if _, err := tpl.Expand(url.Values{}); err == nil {
+			t.Errorf("%s: expected an error", tpl)
+		}
+	}
+}
+
+func TestRevertArgs(t *testing.T) {
+	r := MustCompileRegexp(`^/(\w+)/(?P<id>\d+)/(?P<slug>[a-z]+)$`)
+	s, err := r.RevertArgs("users", "42", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "/users/42/hello" {
+		t.Errorf("expected %q, got %q", "/users/42/hello", s)
+	}
+	if _, err := r.RevertArgs("users", "42"); err == nil {
+		t.Errorf("expected an error for a missing argument")
+	}
+	s, err = r.RevertPairs("", "users", "slug", "hello", "id", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "/users/42/hello" {
+		t.Errorf("expected %q, got %q", "/users/42/hello", s)
+	}
+	if _, err := r.RevertPairs("", "users", "id"); err == nil {
+		t.Errorf("expected an error for an odd number of parameters")
+	}
+	if _, err := r.RevertPairs("", "users", "id", "42"); err == nil {
+		t.Errorf("expected an error for a missing key")
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	r := MustCompileRegexp(`^/(\w+)/(?P<id>\d+)$`)
+	tests := []struct {
+		values url.Values
+		err    string
+	}{
+		{url.Values{"": {"users"}, "id": {"42"}}, ""},
+		{url.Values{"id": {"42"}}, ""},
+		{url.Values{"": {"users"}, "id": {"abc"}},
+			`Invalid value "abc" for variable "id": it doesn't match "[0-9]+"`},
+		{url.Values{"": {"a/b"}, "id": {"42"}},
+			`Invalid value "a/b" for positional variable 0: it doesn't match "[0-9A-Z_a-z]+"`},
+	}
+	for _, v := range tests {
+		err := r.ValidateValues(v.values)
+		if v.err == "" && err != nil {
+			t.Errorf("%v: unexpected error %v", v.values, err)
+		} else if v.err != "" && (err == nil || err.Error() != v.err) {
+			t.Errorf("%v: expected error %q, got %v", v.values, v.err, err)
+		}
+	}
+	m := MustNewGorillaPath("/users/{id:[0-9]+}", false)
+	err := m.Build(&url.URL{}, url.Values{"id": {"joe"}})
+	if err == nil || err.Error() != `Invalid value "joe" for variable "id": it doesn't match "[0-9]+"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	const pattern = `(?P<foo>\d)(?P<bar>\d)(?P<foo>\d)`
+	if _, err := CompileRegexpPolicy(pattern, DuplicatesRejected); err == nil {
+		t.Errorf("%q: expected error for duplicated names", pattern)
+	}
+	r, err := CompileRegexpPolicy(pattern, DuplicatesIndexed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := []string{"foo[0]", "bar", "foo[1]"}
+	if !stringSliceEqual(groups, r.Groups()) {
+		t.Errorf("Expected %v, got %v", groups, r.Groups())
+	}
+	values := url.Values{"foo[0]": {"1"}, "bar": {"2"}, "foo[1]": {"3"}}
+	if v := r.Values("123"); !equalValues(values, v) {
+		t.Errorf("Expected %v, got %v", values, v)
+	}
+	reverted, err := r.RevertValid(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != "123" {
+		t.Errorf("Expected %q, got %q", "123", reverted)
+	}
+}
+
+func TestRepeatedNames(t *testing.T) {
+	r, err := CompileRegexp(`^/(?P<foo>\d+)/(?P<bar>\d)/(?P<foo>[a-z]+)/(\d)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := r.Occurrences(); !intSliceEqual([]int{0, 0, 1, 0}, o) {
+		t.Errorf("Expected %v, got %v", []int{0, 0, 1, 0}, o)
+	}
+	values := url.Values{"foo": {"12", "abc"}, "bar": {"3"}, "": {"4"}}
+	if v := r.Values("/12/3/abc/4"); !equalValues(values, v) {
+		t.Errorf("Expected %v, got %v", values, v)
+	}
+	reverted, err := r.RevertValid(copyValues(values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != "/12/3/abc/4" {
+		t.Errorf("Expected %q, got %q", "/12/3/abc/4", reverted)
+	}
+	tests := []struct {
+		values url.Values
+		err    string
+	}{
+		{url.Values{"foo": {"abc", "12"}, "bar": {"3"}, "": {"4"}},
+			`Invalid value "abc" for occurrence 0 of variable "foo": it doesn't match "[0-9]+"`},
+		{url.Values{"foo": {"12", "34"}, "bar": {"3"}, "": {"4"}},
+			`Invalid value "34" for occurrence 1 of variable "foo": it doesn't match "[a-z]+"`},
+		{url.Values{"foo": {"12", "abc"}, "bar": {"x"}, "": {"4"}},
+			`Invalid value "x" for variable "bar": it doesn't match "[0-9]"`},
+	}
+	for _, test := range tests {
+		_, err := r.RevertValid(test.values)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%v: expected error %q, got %v", test.values, test.err, err)
+		}
+	}
+}
+
+func TestQuantifiedGroups(t *testing.T) {
+	r, err := CompileRegexp(`^/items(?:/(?P<id>\d+))?/(?P<page>\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !intSliceEqual([]int{1, 2}, r.Indices()) {
+		t.Errorf("Expected %v, got %v", []int{1, 2}, r.Indices())
+	}
+	optional := r.Optional()
+	if len(optional) != 2 || !optional[0] || optional[1] {
+		t.Errorf("Expected [true false], got %v", optional)
+	}
+	tests := []struct {
+		values url.Values
+		result string
+	}{
+		{url.Values{"id": {"5"}, "page": {"2"}}, "/items/5/2"},
+		{url.Values{"page": {"2"}}, "/items/2"},
+	}
+	for _, test := range tests {
+		reverted, err := r.RevertValid(copyValues(test.values))
+		if err != nil {
+			t.Errorf("%v: %v", test.values, err)
+		} else if reverted != test.result {
+			t.Errorf("Expected %q, got %q", test.result, reverted)
+		}
+		if values := r.Values(test.result); !equalValues(test.values, values) {
+			t.Errorf("Expected %v, got %v", test.values, values)
+		}
+	}
+	r, err = CompileRegexp(`^/(?:(?P<a>[a-z]+)|(?P<b>\d+))$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stringSliceEqual([]string{"a"}, r.Groups()) {
+		t.Errorf("Expected %v, got %v", []string{"a"}, r.Groups())
+	}
+}
+
+func TestAlternation(t *testing.T) {
+	tests := []struct {
+		pattern string
+		values  url.Values
+		result  string
+	}{
+		{`^/(?:foo|bar)/x$`, url.Values{}, "/foo/x"},
+		{`^/(?:users|people)/(?P<id>\d+)$`, url.Values{"id": {"1"}}, "/users/1"},
+		{`^/foo(?:|bar)/(?P<id>\d+)$`, url.Values{"id": {"1"}}, "/foo/1"},
+		{`^/(?:api/v1|api/v2)/x$`, url.Values{}, "/api/v1/x"},
+	}
+	for _, test := range tests {
+		r, err := CompileRegexp(test.pattern)
+		if err != nil {
+			t.Errorf("%s: %v", test.pattern, err)
+			continue
+		}
+		reverted, err := r.RevertValid(test.values)
+		if err != nil {
+			t.Errorf("%s: %v", test.pattern, err)
+		} else if reverted != test.result {
+			t.Errorf("%s: expected %q, got %q", test.pattern, test.result, reverted)
+		}
+	}
+	for _, pattern := range []string{`^/(?:\d+|x)/y$`, `^/(?:fo+|bar)$`} {
+		if _, err := CompileRegexp(pattern); err == nil {
+			t.Errorf("%s: expected error", pattern)
+		}
+	}
+}
+
+func TestStrictTemplate(t *testing.T) {
+	valid := []string{
+		`^/users/(?P<id>\d+)/?$`,
+		`^/(?:(?P<lang>en|fr)/)?x*$`,
+		`\A/a\b(x|y)\z`,
+	}
+	for _, pattern := range valid {
+		if _, err := CompileRegexpWith(pattern, WithStrictTemplate()); err != nil {
+			t.Errorf("%s: %v", pattern, err)
+		}
+	}
+	tests := []struct {
+		pattern   string
+		construct string
+		offset    int
+	}{
+		{`^/(?:foo|bar)/x$`, "alternation", 2},
+		{`^/v[12]/x$`, "character class", 3},
+		{`^/a+/(\d+)$`, "quantified expression", 2},
+		{`^/a$/(\d+)`, "anchor", 3},
+		{`^/a/^(\d+)`, "anchor", 4},
+		{`^/.(\d+)`, "wildcard", 2},
+		{`^/\d(\d+)`, "character class", -1},
+	}
+	for _, test := range tests {
+		_, err := CompileRegexpWith(test.pattern, WithStrictTemplate())
+		e, ok := err.(*TemplateError)
+		if !ok {
+			t.Errorf("%s: expected a TemplateError, got %v", test.pattern, err)
+			continue
+		}
+		if e.Construct != test.construct || e.Offset != test.offset {
+			t.Errorf("%s: expected %s at %d, got %s at %d", test.pattern,
+				test.construct, test.offset, e.Construct, e.Offset)
+		}
+	}
+	m, err := NewGorillaPath("/users/{id:[0-9]+}/", true, WithStrictTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RevertValid(url.Values{"id": {"1"}}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRepeatedGroups(t *testing.T) {
+	tests := []struct {
+		pattern string
+		values  url.Values
+		result  string
+		valid   bool
+	}{
+		{`^/files(/[a-z]+)*$`, url.Values{"": {"/a", "/b"}}, "/files/a/b", true},
+		{`^/files(/[a-z]+)*$`, url.Values{}, "/files", true},
+		{`^/(?P<part>[a-z]+/)+x$`, url.Values{"part": {"a/", "b/", "c/"}}, "/a/b/c/x", true},
+		{`^/(?P<part>[a-z]+/)+x$`, url.Values{}, "", false},
+		{`^/tags(?:/(?P<tag>[a-z]+)){1,2}/(?P<id>\d+)$`, url.Values{"tag": {"a", "b"}, "id": {"1"}}, "/tags/a/b/1", true},
+		{`^/tags(?:/(?P<tag>[a-z]+)){1,2}/(?P<id>\d+)$`, url.Values{"tag": {"a", "b", "c"}, "id": {"1"}}, "", false},
+		{`^/tags(?:/(?P<tag>[a-z]+))*/(?P<tag>\d+)$`, url.Values{"tag": {"a", "b", "1"}}, "/tags/a/b/1", true},
+		{`^/tags(?:/(?P<tag>[a-z]+))*$`, url.Values{"tag": {"a", "B"}}, "", false},
+	}
+	for _, test := range tests {
+		r, err := CompileRegexp(test.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reverted, err := r.RevertValid(copyValues(test.values))
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s %v: expected error, got %q", test.pattern, test.values, reverted)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: %v", test.pattern, test.values, err)
+		} else if reverted != test.result {
+			t.Errorf("%s: expected %q, got %q", test.pattern, test.result, reverted)
+		}
+	}
+	r := MustCompileRegexp(`^/(?:(?P<part>[a-z]+)/)+$`)
+	if optional := r.Optional(); len(optional) != 1 || optional[0] {
+		t.Errorf("Expected [false], got %v", optional)
+	}
+}
+
+func TestCompileRegexpSyntax(t *testing.T) {
+	r, err := CompileRegexpSyntax(`^/x/(a|ab)`, syntax.POSIX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := r.Values("/x/ab"); values.Get("") != "ab" {
+		t.Errorf("Expected leftmost-longest match %q, got %v", "ab", values)
+	}
+	reverted, err := r.RevertValid(url.Values{"": {"ab"}})
+	if err != nil || reverted != "/x/ab" {
+		t.Errorf("Expected %q, got %q (%v)", "/x/ab", reverted, err)
+	}
+	r, err = CompileRegexpSyntax(`^/(?P<id>\d+)$`, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := r.Values("/1"); values.Get("id") != "1" {
+		t.Errorf("Expected id=1, got %v", values)
+	}
+	invalid := []struct {
+		pattern string
+		flags   syntax.Flags
+	}{
+		{`^/(?P<id>\d+)$`, syntax.POSIX},
+		{`^/(\pL+)$`, syntax.Perl &^ syntax.UnicodeGroups},
+	}
+	for _, v := range invalid {
+		if _, err := CompileRegexpSyntax(v.pattern, v.flags); err == nil {
+			t.Errorf("%s: expected error", v.pattern)
+		}
+	}
+}
+
+func TestMust(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for invalid pattern")
+		}
+	}()
+	MustCompileRegexp(`^/foo$`)
+	MustNewGorillaPath("/{id:[0-9]+}", false)
+	MustNewGorillaPath("/{id", false)
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	patterns := []string{
+		`^1(\d+)3$`,
+		`^/users/(?P<id>[0-9]+)/(?P<name>[^/]+)$`,
+		`^/items(?:/(?P<id>\d+))?$`,
+		`^(?P<sub>[a-z]+)\.domain\.com$`,
+		`^/(x|y)(?P<n>\d{1,4})$`,
+	}
+	for _, pattern := range patterns {
+		if err := CheckRoundTrip(MustCompileRegexp(pattern), 50); err != nil {
+			t.Errorf("%q: %v", pattern, err)
+		}
+	}
+	// Quantified literals are not written to the template, so they can't
+	// round trip; strict templates reject them when compiling.
+	if _, err := CompileRegexpWith(`^/a{2,3}/(\d+)$`, WithStrictTemplate()); err == nil {
+		t.Errorf("expected strict template error")
+	}
+}
+
+func TestGroupLiterals(t *testing.T) {
+	r, err := CompileRegexpWith(`^/(?P<user>user-\d+)/(?P<file>[a-z]+\.txt)/([a-z]+-\d+)$`, WithGroupLiterals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl := r.Template(); tpl != "/user-%s/%s.txt/%s" {
+		t.Errorf("expected template %q, got %q", "/user-%s/%s.txt/%s", tpl)
+	}
+	values := url.Values{"user": {"42"}, "file": {"notes"}, "": {"a-1"}}
+	if v := r.Values("/user-42/notes.txt/a-1"); !equalValues(values, v) {
+		t.Errorf("expected %v, got %v", values, v)
+	}
+	reverted, err := r.RevertValid(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted != "/user-42/notes.txt/a-1" {
+		t.Errorf("expected %q, got %q", "/user-42/notes.txt/a-1", reverted)
+	}
+}
+
+func TestVisit(t *testing.T) {
+	re := MustCompileRegexp(`^(?:/(?P<lang>en|fr))?/users/(?P<id>\d+)/(\w+)$`)
+	re.defaults = map[string]string{"lang": "en"}
+	var names, values []string
+	ok := re.Visit("/users/42/posts", func(name, value string) bool {
+		names, values = append(names, name), append(values, value)
+		return true
+	})
+	if !ok || !stringSliceEqual(names, []string{"id", "", "lang"}) ||
+		!stringSliceEqual(values, []string{"42", "posts", "en"}) {
+		t.Errorf("unexpected visit %v %v %v", ok, names, values)
+	}
+	var id string
+	re.Visit("/fr/users/42/posts", func(name, value string) bool {
+		if name == "id" {
+			id = value
+			return false
+		}
+		if name != "lang" {
+			t.Errorf("unexpected visit of %q after stopping", name)
+		}
+		return true
+	})
+	if id != "42" {
+		t.Errorf("expected %q, got %q", "42", id)
+	}
+	if re.Visit("/users/abc/posts", func(name, value string) bool { return true }) {
+		t.Errorf("expected no match")
+	}
+	dst := re.AppendValues([]string{"x"}, "/users/42/posts")
+	if !stringSliceEqual(dst, []string{"x", "en", "42", "posts"}) {
+		t.Errorf("unexpected values %v", dst)
+	}
+	if dst := re.AppendValues(nil, "/users/abc"); dst != nil {
+		t.Errorf("expected nil, got %v", dst)
+	}
+	expect := url.Values{"lang": {"en"}, "id": {"42"}, "": {"posts"}}
+	if v := re.Values("/users/42/posts"); !equalValues(expect, v) {
+		t.Errorf("expected %v, got %v", expect, v)
+	}
+}
+
+func BenchmarkValues(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		re.Values("/users/42/posts/7")
+	}
+}
+
+func BenchmarkVisit(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	var id string
+	visit := func(name, value string) bool {
+		if name == "id" {
+			id = value
+			return false
+		}
+		return true
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		re.Visit("/users/42/posts/7", visit)
+	}
+	_ = id
+}
+
+func BenchmarkAppendValues(b *testing.B) {
+	re := MustCompileRegexp(`^/users/(?P<id>\d+)/posts/(?P<post>\d+)$`)
+	dst := make([]string, 0, 2)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst = re.AppendValues(dst[:0], "/users/42/posts/7")
+	}
+}
+
+func BenchmarkGorillaPathExtract(b *testing.B) {
+	m := MustNewGorillaPath("/users/{id:[0-9]+}/posts/{post:[0-9]+}", false)
+	r, err := http.NewRequest("GET", "http://domain.com/users/42/posts/7", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.Extract(&Result{}, r)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewRewrite returns a rewrite from a source path template to a destination
+// template, both in Gorilla's syntax. The destination can have a query, and
+// its values can use variables too:
+//
+//	rw, err := reverse.NewRewrite("/v1/users/{id}", "/api/users/{id}?version=1")
+func NewRewrite(src, dst string, opts ...Option) (*Rewrite, error) {
+	source, err := NewGorillaPath(src, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+	path, query := dst, ""
+	if i := strings.Index(dst, "?"); i != -1 {
+		path, query = dst[:i], dst[i+1:]
+	}
+	dest, err := NewGorillaPath(path, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+	builders := []Builder{dest}
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value := pair, ""
+		if i := strings.Index(pair, "="); i != -1 {
+			key, value = pair[:i], pair[i+1:]
+		}
+		q, err := NewGorillaQuery(key, value, opts...)
+		if err != nil {
+			return nil, err
+		}
+		builders = append(builders, q)
+	}
+	return &Rewrite{Source: source, Dest: NewURLBuilder(builders...),
+		src: src, dst: dst}, nil
+}
+
+// MustNewRewrite is like NewRewrite but panics if a template can't be
+// compiled.
+func MustNewRewrite(src, dst string, opts ...Option) *Rewrite {
+	rw, err := NewRewrite(src, dst, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("reverse: NewRewrite(%q, %q): %v", src, dst, err))
+	}
+	return rw
+}
+
+// Rewrite rewrites request URLs matching a source path: the variables it
+// extracts are reverted into the destination. The request query is kept,
+// and the destination query values replace the ones with the same key.
+type Rewrite struct {
+	Source *GorillaPath
+	Dest   Builder
+	src    string
+	dst    string
+}
+
+func (rw *Rewrite) Match(r *http.Request) bool {
+	return rw.Source.Match(r)
+}
+
+func (rw *Rewrite) String() string {
+	return fmt.Sprintf("Rewrite(%q, %q)", rw.src, rw.dst)
+}
+
+// URL returns the rewritten URL for a request matching the source path.
+func (rw *Rewrite) URL(r *http.Request) (*url.URL, error) {
+	if !rw.Source.Match(r) {
+		return nil, fmt.Errorf("URL path %q doesn't match %q", r.URL.Path,
+			rw.src)
+	}
+	result := Result{}
+	rw.Source.Extract(&result, r)
+	u := *r.URL
+	u.Path, u.RawPath = "", ""
+	if err := rw.Dest.Build(&u, result.Values); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Handler returns a handler that rewrites the URL of matching requests
+// before calling h. Other requests are passed unchanged.
+func (rw *Rewrite) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rw.Source.Match(r) {
+			u, err := rw.URL(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = u
+			r = r2
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		src      string
+		dst      string
+		path     string
+		expected string
+	}{
+		{"/v1/users/{id}", "/api/users/{id}?version=1", "/v1/users/1", "/api/users/1?version=1"},
+		{"/v1/users/{id}", "/api/users/{id}?version=1", "/v1/users/1?q=a&version=0", "/api/users/1?q=a&version=1"},
+		{"/v1/{kind}/{id:[0-9]+}", "/api/{kind}?id={id}", "/v1/posts/2", "/api/posts?id=2"},
+		{"/v1/users/{id}", "/api/users/{id}", "/v2/users/1", "/v2/users/1"},
+	}
+	for _, v := range tests {
+		rw := MustNewRewrite(v.src, v.dst)
+		var got string
+		h := rw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.URL.RequestURI()
+		}))
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if got != v.expected {
+			t.Errorf("%s -> %s: expected %q for %q, got %q", v.src, v.dst,
+				v.expected, v.path, got)
+		}
+		if r.URL.RequestURI() != v.path {
+			t.Errorf("%s: the original request was modified", v.path)
+		}
+	}
+	if _, err := NewRewrite("/users/{id}", "/api/{id"); err == nil {
+		t.Errorf("expected error for invalid destination")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// CheckRoundTrip verifies the main invariant of a reversible regexp: the
+// values extracted from a matching string can be reverted to a string that
+// matches again and yields the same values.
+//
+// It generates the given number of sample strings matching the regexp,
+// walking its syntax tree with a fixed seed so results are reproducible,
+// and returns an error describing the first sample that fails.
+func CheckRoundTrip(r *Regexp, samples int) error {
+	re, err := syntax.Parse(r.compiled.String(), syntax.Perl)
+	if err != nil {
+		return err
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < samples; i++ {
+		var b strings.Builder
+		generate(&b, re, rnd)
+		sample := b.String()
+		values := r.Values(sample)
+		if values == nil {
+			// The generator can't honor some assertions, like word
+			// boundaries; skip those samples.
+			continue
+		}
+		reverted, err := r.RevertValid(cloneValues(values))
+		if err != nil {
+			return fmt.Errorf("Round trip failed for %q with values %v: %v",
+				sample, values, err)
+		}
+		if v := r.Values(reverted); !sameValues(values, v) {
+			return fmt.Errorf("Round trip failed for %q: reverted to %q "+
+				"with values %v, expected %v", sample, reverted, v, values)
+		}
+	}
+	return nil
+}
+
+// maxRepeat is the maximum number of repetitions generated for unbounded
+// quantifiers.
+const maxRepeat = 3
+
+// sampleRunes are the preferred runes to generate for character classes.
+const sampleRunes = "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-_"
+
+// generate writes a random string matching the given regexp. With a nil
+// rnd, it writes the shortest one instead, choosing the first alternative
+// and the most readable runes, so that the result is deterministic.
+func generate(b *strings.Builder, re *syntax.Regexp, rnd *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(re.Rune, rnd))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteByte(sampleRunes[intn(rnd, len(sampleRunes))])
+	case syntax.OpCapture:
+		generate(b, re.Sub[0], rnd)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generate(b, sub, rnd)
+		}
+	case syntax.OpAlternate:
+		generate(b, re.Sub[intn(rnd, len(re.Sub))], rnd)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatRange(re)
+		for n := min + intn(rnd, max-min+1); n > 0; n-- {
+			generate(b, re.Sub[0], rnd)
+		}
+	}
+}
+
+// intn returns a random number in [0, n), or 0 if rnd is nil.
+func intn(rnd *rand.Rand, n int) int {
+	if rnd == nil {
+		return 0
+	}
+	return rnd.Intn(n)
+}
+
+// repeatRange returns the number of repetitions to generate for a
+// quantified regexp.
+func repeatRange(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	max := re.Max
+	if max < 0 {
+		max = re.Min + maxRepeat
+	}
+	return re.Min, max
+}
+
+// classRune returns a rune from a character class, given as rune ranges.
+// Readable runes are preferred.
+func classRune(ranges []rune, rnd *rand.Rand) rune {
+	var candidates []rune
+	for _, r := range sampleRunes {
+		for i := 0; i < len(ranges); i += 2 {
+			if r >= ranges[i] && r <= ranges[i+1] {
+				candidates = append(candidates, r)
+				break
+			}
+		}
+	}
+	if len(candidates) != 0 {
+		return candidates[intn(rnd, len(candidates))]
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+	if rnd == nil {
+		for i := 0; i < len(ranges); i += 2 {
+			for r := ranges[i]; r <= ranges[i+1] && r-ranges[i] < 128; r++ {
+				if unicode.IsPrint(r) && r != ' ' {
+					return r
+				}
+			}
+		}
+		return ranges[0]
+	}
+	i := rnd.Intn(len(ranges)/2) * 2
+	return ranges[i] + rune(rnd.Intn(int(ranges[i+1]-ranges[i])+1))
+}
@@ -0,0 +1,97 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteEntry pairs a matcher with the handler called when it matches.
+// Metadata is available in the match result, like for Route.
+type RouteEntry struct {
+	Matcher  Matcher
+	Handler  http.Handler
+	Metadata map[string]interface{}
+}
+
+// RouteList is a minimal dispatcher: it calls the handler of the first entry
+// that matches the request.
+//
+//	var routes reverse.RouteList
+//	routes.Add(reverse.MustNewGorillaPath("/users/{id}", false), users)
+//	routes.Add(reverse.NewPathPrefix("/static/"), static)
+//	http.ListenAndServe(":8080", &routes)
+//
+// Unlike a Router, it doesn't have named routes to build URLs.
+type RouteList struct {
+	Entries []RouteEntry
+	// NotFoundHandler is called when no entry matches. If nil,
+	// http.NotFoundHandler is used.
+	NotFoundHandler http.Handler
+	// MethodNotAllowedHandler is called when no entry matches, but some
+	// would with a different method; the Allow header is set before. If
+	// nil, a 405 error is replied.
+	MethodNotAllowedHandler http.Handler
+}
+
+// Add appends an entry to the list.
+func (l *RouteList) Add(m Matcher, h http.Handler) *RouteList {
+	l.Entries = append(l.Entries, RouteEntry{Matcher: m, Handler: h})
+	return l
+}
+
+// Match returns the result of the first entry that matches the request: the
+// entry handler, unless an extractor set a different one, and the extracted
+// variables. It returns nil if no entry matches.
+func (l *RouteList) Match(r *http.Request) *Result {
+	info := NewRequestInfo(r)
+	for _, e := range l.Entries {
+		if MatchInfo(e.Matcher, info) {
+			result := &Result{}
+			extractMatcher(e.Matcher, result, r)
+			if result.Handler == nil {
+				result.Handler = e.Handler
+			}
+			result.Matcher, result.Metadata = e.Matcher, e.Metadata
+			return result
+		}
+	}
+	return nil
+}
+
+// AllowedMethods returns the methods allowed by the entries that would match
+// the request with a different method. See the AllowedMethods function.
+func (l *RouteList) AllowedMethods(r *http.Request) []string {
+	matchers := make([]Matcher, len(l.Entries))
+	for k, e := range l.Entries {
+		matchers[k] = e.Matcher
+	}
+	return collectAllowedMethods(matchers, r)
+}
+
+// ServeHTTP dispatches the request to the handler of the first matching
+// entry, with the result and its variables stored in the request context. If
+// no entry matches, it replies with 405 if some would match with a different
+// method, or 404 otherwise.
+func (l *RouteList) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := l.Match(r)
+	if result == nil {
+		h := l.NotFoundHandler
+		if h == nil {
+			h = http.NotFoundHandler()
+		}
+		if allowed := l.AllowedMethods(r); len(allowed) != 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			h = l.MethodNotAllowedHandler
+			if h == nil {
+				h = http.HandlerFunc(methodNotAllowed)
+			}
+		}
+		h.ServeHTTP(w, r)
+		return
+	}
+	result.Handler.ServeHTTP(w, SetResult(r, result))
+}
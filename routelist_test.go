@@ -0,0 +1,94 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteList(t *testing.T) {
+	var routes RouteList
+	routes.Add(MustNewGorillaPath("/users/{id:[0-9]+}", false),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "user %s", Vars(r).Get("id"))
+		}))
+	routes.Add(NewPathPrefix("/users/"),
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "users")
+		}))
+	routes.Add(NewPathRedirect("/old"), nil)
+	tests := []struct {
+		rURL string
+		code int
+		body string
+	}{
+		{"http://domain.com/users/42", http.StatusOK, "user 42"},
+		{"http://domain.com/users/abc", http.StatusOK, "users"},
+		{"http://domain.com/old/", http.StatusMovedPermanently, ""},
+		{"http://domain.com/other", http.StatusTeapot, "not found"},
+	}
+	routes.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		fmt.Fprint(w, "not found")
+	})
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, r)
+		if w.Code != v.code || v.body != "" && w.Body.String() != v.body {
+			t.Errorf("%s: expected %d %q, got %d %q", v.rURL, v.code, v.body,
+				w.Code, w.Body.String())
+		}
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := routes.Match(r); result == nil || result.Values.Get("id") != "42" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestRouteListMethodNotAllowed(t *testing.T) {
+	var routes RouteList
+	h := http.NotFoundHandler()
+	routes.Add(NewAll([]Matcher{NewMethod([]string{"GET"}),
+		MustNewGorillaPath("/users/{id}", false)}), h)
+	routes.Add(NewRouteSpec().Path("/users/{id}").Methods("PUT", "GET"), h)
+	routes.Add(NewAll([]Matcher{NewMethod([]string{"DELETE"}),
+		NewPath("/other")}), h)
+	r, err := http.NewRequest("POST", "http://domain.com/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("expected 405 with GET, PUT, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	routes.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, r)
+	if w.Code != http.StatusTeapot || w.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("expected custom handler, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	r, err = http.NewRequest("POST", "http://domain.com/missing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || w.Header().Get("Allow") != "" {
+		t.Errorf("expected 404, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+}
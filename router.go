@@ -0,0 +1,323 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Router ---------------------------------------------------------------------
+
+// NewRouter returns an empty router.
+func NewRouter() *Router {
+	return &Router{named: map[string]*Route{}}
+}
+
+// Router dispatches requests to the handler of the first registered route
+// that matches, and builds URLs for named routes.
+//
+// The variables extracted by the route are available to the handler
+// calling Vars.
+type Router struct {
+	// NotFoundHandler is called when no route matches. If nil,
+	// http.NotFoundHandler is used.
+	NotFoundHandler http.Handler
+	// MethodNotAllowedHandler is called when no route matches, but some
+	// would with a different method; the Allow header is set before. If
+	// nil, a 405 error is replied.
+	MethodNotAllowedHandler http.Handler
+	// SlashPolicy, if not SlashStrict, is applied when no route matches:
+	// the request path is tried with the trailing slash added or removed,
+	// as the policy allows, and the request is redirected to it, or served
+	// for SlashIgnore.
+	SlashPolicy SlashPolicy
+	// Hook, if not nil, is called when each route is matched or built.
+	Hook        Hook
+	routes      []*Route
+	named       map[string]*Route
+	middlewares []Middleware
+}
+
+// Handle registers a route composed of all the given matchers. The name is
+// used to build URLs and can be empty; it panics if the name was already
+// registered.
+func (r *Router) Handle(name string, h http.Handler, matchers ...Matcher) *Route {
+	route := &Route{Name: name, Matcher: NewAll(matchers), Handler: h}
+	r.add(route)
+	return route
+}
+
+// HandleFunc registers a route composed of all the given matchers, with
+// a handler function.
+func (r *Router) HandleFunc(name string, f func(http.ResponseWriter, *http.Request), matchers ...Matcher) *Route {
+	return r.Handle(name, http.HandlerFunc(f), matchers...)
+}
+
+// NewRoute returns an empty route definition; its Handler method registers
+// the route in the router:
+//
+//	_, err := router.NewRoute().
+//		Name("user").
+//		Host("{sub}.example.com").
+//		PathPrefix("/api").
+//		Path("/users/{id}").
+//		Methods("GET", "POST").
+//		Handler(h)
+func (r *Router) NewRoute() *RouteSpec {
+	return &RouteSpec{router: r}
+}
+
+// Get returns the route registered with the given name, or nil.
+func (r *Router) Get(name string) *Route {
+	return r.named[name]
+}
+
+// Describe returns the descriptions of the registered routes, in
+// registration order, which is their priority.
+func (r *Router) Describe() []RouteDescription {
+	routes := make([]RouteDescription, len(r.routes))
+	for k, v := range r.routes {
+		routes[k] = Describe(v.Name, v.Matcher)
+		routes[k].Priority = k
+	}
+	return routes
+}
+
+// Dump writes the registered routes as an aligned text table; see
+// DumpRoutes.
+func (r *Router) Dump(w io.Writer) error {
+	return DumpRoutes(w, r.Describe())
+}
+
+// Routes returns the registered routes, in registration order.
+func (r *Router) Routes() []*Route {
+	return r.routes
+}
+
+// Match returns the first route that matches the request, and the result of
+// extracting its variables. The result handler is the route handler unless
+// an extractor set a different one, like a redirect, wrapped with the router
+// and route middlewares. It returns nil if no route matches, even after
+// applying the slash policy.
+func (r *Router) Match(req *http.Request) (*Route, *Result) {
+	route, result := r.matchRoute(req)
+	if route == nil {
+		route, result = r.matchSlash(req)
+	}
+	if route == nil {
+		return nil, nil
+	}
+	if result.Handler == nil {
+		result.Handler = route.Handler
+	}
+	result.Route, result.Matcher = route.Name, route
+	result.Metadata = route.Metadata
+	result.Wrap(route.middlewares...)
+	result.Wrap(r.middlewares...)
+	return route, result
+}
+
+// matchRoute returns the first route that matches the request, and the
+// result of extracting its variables.
+func (r *Router) matchRoute(req *http.Request) (*Route, *Result) {
+	info := NewRequestInfo(req)
+	for _, route := range r.routes {
+		if r.match(route, info) {
+			result := &Result{}
+			route.Extract(result, req)
+			return route, result
+		}
+	}
+	return nil, nil
+}
+
+// matchSlash matches the request with the trailing slash added or removed,
+// according to the slash policy. Unless the policy is SlashIgnore, the
+// result handler redirects to the matching path.
+func (r *Router) matchSlash(req *http.Request) (*Route, *Result) {
+	path, ok := r.SlashPolicy.alternate(req.URL.Path)
+	if !ok {
+		return nil, nil
+	}
+	u := withTrailingSlash(req.URL, strings.HasSuffix(path, "/"))
+	alt := req.WithContext(req.Context())
+	alt.URL = u
+	route, result := r.matchRoute(alt)
+	if route != nil && r.SlashPolicy != SlashIgnore {
+		result.Handler = http.RedirectHandler(u.String(),
+			http.StatusMovedPermanently)
+	}
+	return route, result
+}
+
+// ServeHTTP dispatches the request to the handler of the first matching
+// route, with the result and its variables stored in the request context;
+// see MatchResult.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route, result := r.Match(req)
+	if route == nil {
+		h := r.NotFoundHandler
+		if h == nil {
+			h = http.NotFoundHandler()
+		}
+		if allowed := r.allowedMethods(req); len(allowed) != 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			h = r.MethodNotAllowedHandler
+			if h == nil {
+				h = http.HandlerFunc(methodNotAllowed)
+			}
+		}
+		h.ServeHTTP(w, req)
+		return
+	}
+	result.Handler.ServeHTTP(w, SetResult(req, result))
+}
+
+// URL builds a URL for the named route using the given variables, which are
+// not modified.
+func (r *Router) URL(name string, values url.Values) (*url.URL, error) {
+	route := r.named[name]
+	if route == nil {
+		return nil, fmt.Errorf("Route %q not found", name)
+	}
+	u := &url.URL{}
+	err := route.Build(u, cloneValues(values))
+	if r.Hook != nil {
+		r.Hook.OnBuild(route, u, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// add registers a route. It panics if the name was already registered.
+func (r *Router) add(route *Route) {
+	if _, ok := r.named[route.Name]; ok && route.Name != "" {
+		panic("reverse: multiple registrations for route " + route.Name)
+	}
+	r.routes = append(r.routes, route)
+	if route.Name != "" {
+		r.named[route.Name] = route
+	}
+}
+
+// allowedMethods returns the methods allowed by the routes that would match
+// the request with a different method.
+func (r *Router) allowedMethods(req *http.Request) []string {
+	matchers := make([]Matcher, len(r.routes))
+	for k, v := range r.routes {
+		matchers[k] = v
+	}
+	return collectAllowedMethods(matchers, req)
+}
+
+// collectAllowedMethods returns the methods allowed by the matchers that
+// would match the request with a different method, without duplicates.
+func collectAllowedMethods(matchers []Matcher, req *http.Request) []string {
+	var allowed []string
+	seen := map[string]bool{}
+	for _, m := range matchers {
+		methods, _ := AllowedMethods(m, req)
+		for _, v := range methods {
+			if !seen[v] {
+				seen[v] = true
+				allowed = append(allowed, v)
+			}
+		}
+	}
+	return allowed
+}
+
+// methodNotAllowed replies with a 405 error.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+		http.StatusMethodNotAllowed)
+}
+
+// match matches a route, calling the hook if there is one.
+func (r *Router) match(route *Route, info *RequestInfo) bool {
+	if r.Hook == nil {
+		return route.MatchInfo(info)
+	}
+	req := info.Request
+	r.Hook.OnMatchStart(route, req)
+	start := time.Now()
+	matched := route.MatchInfo(info)
+	r.Hook.OnMatchEnd(route, req, matched, time.Since(start))
+	return matched
+}
+
+// Route ----------------------------------------------------------------------
+
+// Route is a matcher registered in a Router with a handler.
+//
+// Metadata is route-level configuration for middlewares, like required
+// scopes or a rate limit class, available in the match result:
+//
+//	router.Handle("admin", h, reverse.NewPathPrefix("/admin/")).
+//		Meta("scopes", []string{"admin"})
+//	// In a middleware:
+//	scopes, _ := reverse.MatchResult(r).Metadata["scopes"].([]string)
+type Route struct {
+	Name        string
+	Matcher     Matcher
+	Handler     http.Handler
+	Metadata    map[string]interface{}
+	middlewares []Middleware
+}
+
+// Meta sets a metadata value for the route.
+func (r *Route) Meta(key string, value interface{}) *Route {
+	if r.Metadata == nil {
+		r.Metadata = map[string]interface{}{}
+	}
+	r.Metadata[key] = value
+	return r
+}
+
+func (r *Route) Match(req *http.Request) bool {
+	return r.Matcher.Match(req)
+}
+
+func (r *Route) MatchInfo(info *RequestInfo) bool {
+	return MatchInfo(r.Matcher, info)
+}
+
+func (r *Route) String() string {
+	return fmt.Sprint(r.Matcher)
+}
+
+// Extract returns the variables extracted by the route matchers.
+func (r *Route) Extract(result *Result, req *http.Request) {
+	extractMatcher(r.Matcher, result, req)
+}
+
+// Build builds a URL calling the route builders.
+func (r *Route) Build(u *url.URL, values url.Values) error {
+	return buildMatcher(r.Matcher, u, values)
+}
+
+// Helpers --------------------------------------------------------------------
+
+// extractMatcher calls Extract on the matcher if it is an Extractor.
+func extractMatcher(m Matcher, result *Result, r *http.Request) {
+	if v, ok := m.(Extractor); ok {
+		v.Extract(result, r)
+	}
+}
+
+// buildMatcher calls Build on the matcher if it is a Builder.
+func buildMatcher(m Matcher, u *url.URL, values url.Values) error {
+	if v, ok := m.(Builder); ok {
+		return v.Build(u, values)
+	}
+	return nil
+}
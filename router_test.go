@@ -0,0 +1,141 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s", Vars(r).Get("id"))
+	}, NewMethod([]string{"GET"}), MustNewGorillaPath("/users/{id:[0-9]+}", false))
+	router.HandleFunc("", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "home")
+	}, NewPath("/"))
+
+	type test struct {
+		method string
+		rURL   string
+		code   int
+		body   string
+	}
+	tests := []test{
+		{"GET", "http://domain.com/users/42", http.StatusOK, "user 42"},
+		{"GET", "http://domain.com/", http.StatusOK, "home"},
+		{"POST", "http://domain.com/users/42", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+		{"GET", "http://domain.com/users/abc", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != v.code || w.Body.String() != v.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", v.method, v.rURL, v.code, v.body, w.Code, w.Body.String())
+		}
+	}
+
+	values := url.Values{"id": {"7"}}
+	for i := 0; i < 2; i++ {
+		// The values are not consumed, so they can be used again.
+		u, err := router.URL("user", values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != "/users/7" {
+			t.Errorf("expected %q, got %q", "/users/7", u.String())
+		}
+	}
+	if _, err := router.URL("missing", nil); err == nil {
+		t.Errorf("expected error for unknown route")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for duplicated route name")
+		}
+	}()
+	router.Handle("user", http.NotFoundHandler(), NewPath("/user"))
+}
+
+func TestRouterMatchResult(t *testing.T) {
+	router := NewRouter()
+	var got *Result
+	router.Use(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = MatchResult(r)
+			h.ServeHTTP(w, r)
+		})
+	})
+	users := NewAll([]Matcher{MustNewGorillaPath("/users/{id:[0-9]+}", false)})
+	mount, err := NewMount("/api/{version}", users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := router.Handle("user", http.NotFoundHandler(),
+		MustNewGorillaHost("{tenant}.domain.com"), mount)
+	r, err := http.NewRequest("GET", "http://acme.domain.com/api/v1/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if got == nil {
+		t.Fatal("expected a result in the request context")
+	}
+	if got.Route != "user" || got.Matcher != route ||
+		got.Pattern != "/api/{version}/users/{id:[0-9]+}" ||
+		got.HostPattern != "{tenant}.domain.com" || got.Values.Get("id") != "42" {
+		t.Errorf("unexpected result %+v", got)
+	}
+}
+
+func TestRouteMetadata(t *testing.T) {
+	router := NewRouter()
+	h := http.NotFoundHandler()
+	router.Handle("admin", h, NewPathPrefix("/admin/")).
+		Meta("scopes", []string{"admin"}).
+		Meta("rateLimit", "strict")
+	if _, err := router.NewRoute().Name("user").Path("/users/{id}").
+		Meta("timeout", 5).Handler(h); err != nil {
+		t.Fatal(err)
+	}
+	router.Handle("home", h, NewPath("/"))
+	tests := []struct {
+		path   string
+		key    string
+		expect interface{}
+	}{
+		{"/admin/users", "rateLimit", "strict"},
+		{"/users/42", "timeout", 5},
+		{"/", "timeout", nil},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, result := router.Match(r)
+		if result == nil || result.Metadata[v.key] != v.expect {
+			t.Errorf("%s: expected %v, got %+v", v.path, v.expect, result)
+		}
+	}
+	var routes RouteList
+	routes.Entries = append(routes.Entries, RouteEntry{Matcher: NewPath("/"), Handler: h,
+		Metadata: map[string]interface{}{"public": true}})
+	r, err := http.NewRequest("GET", "http://domain.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := routes.Match(r); result == nil || result.Metadata["public"] != true {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NewRouteSpec returns an empty route definition, which matches any request.
+//
+// Matchers are added calling its methods, which can be chained:
+//
+//	spec := reverse.NewRouteSpec().
+//		Schemes("https").
+//		Host("{tenant}.example.com").
+//		PathPrefix("/api").
+//		Path("/users/{id:[0-9]+}").
+//		Methods("GET", "PUT")
+//	if err := spec.Err(); err != nil {
+//		// ...
+//	}
+//
+// The prefix is prepended to the path, so the example above matches paths
+// like "/api/users/42".
+func NewRouteSpec() *RouteSpec {
+	return &RouteSpec{}
+}
+
+// RouteSpec is a route definition built using chained method calls. It is
+// a Matcher, Extractor and Builder composed of all matchers it was given.
+//
+// If one of the patterns is invalid, the route never matches and building
+// returns the error; Err() returns it.
+type RouteSpec struct {
+	name    string
+	meta    map[string]interface{}
+	router  *Router
+	schemes Scheme
+	host    Matcher
+	prefix  string
+	path    string
+	hasPath bool
+	methods Method
+	headers Header
+	queries Query
+	vars    []Matcher // query variables
+	opts    []Option
+	all     All
+	hook    Hook
+	err     error
+}
+
+// Options sets the options for the host and path matchers, like
+// WithEncoding to match and build escaped paths. It must be called before
+// Host to apply to the host matcher.
+func (s *RouteSpec) Options(opts ...Option) *RouteSpec {
+	s.opts = opts
+	return s.compile()
+}
+
+// Schemes adds a URL scheme matcher. One of the schemes must match, and the
+// first one is used to build URLs with a host.
+func (s *RouteSpec) Schemes(schemes ...string) *RouteSpec {
+	s.schemes = NewScheme(schemes)
+	return s.compile()
+}
+
+// Host adds a URL host matcher using a Gorilla pattern.
+func (s *RouteSpec) Host(pattern string) *RouteSpec {
+	m, err := NewGorillaHost(pattern, s.opts...)
+	if err != nil {
+		return s.fail(err)
+	}
+	s.host = m
+	return s.compile()
+}
+
+// PathPrefix sets a Gorilla pattern for the URL path prefix. If a path is
+// also set, the prefix is prepended to it.
+func (s *RouteSpec) PathPrefix(pattern string) *RouteSpec {
+	s.prefix = pattern
+	return s.compile()
+}
+
+// Path sets a Gorilla pattern for the URL path.
+func (s *RouteSpec) Path(pattern string) *RouteSpec {
+	s.path, s.hasPath = pattern, true
+	return s.compile()
+}
+
+// Methods adds a request method matcher. One of the methods must match.
+func (s *RouteSpec) Methods(methods ...string) *RouteSpec {
+	s.methods = NewMethod(methods)
+	return s.compile()
+}
+
+// Headers adds a header matcher from a list of key/value pairs.
+func (s *RouteSpec) Headers(pairs ...string) *RouteSpec {
+	m, err := mapFromPairs(pairs)
+	if err != nil {
+		return s.fail(err)
+	}
+	s.headers = NewHeader(m)
+	return s.compile()
+}
+
+// Queries adds a URL query matcher from a list of key/value pairs. Values
+// containing variables, like `{page:[0-9]+}`, use a GorillaQuery.
+func (s *RouteSpec) Queries(pairs ...string) *RouteSpec {
+	m, err := mapFromPairs(pairs)
+	if err != nil {
+		return s.fail(err)
+	}
+	s.queries, s.vars = Query{}, nil
+	for i := 0; i < len(pairs); i += 2 {
+		key, value := pairs[i], pairs[i+1]
+		if !strings.Contains(value, "{") {
+			s.queries[key] = m[key]
+			continue
+		}
+		q, err := NewGorillaQuery(key, value)
+		if err != nil {
+			return s.fail(err)
+		}
+		s.vars = append(s.vars, q)
+	}
+	return s.compile()
+}
+
+// Name sets the name of the route returned by Handler.
+func (s *RouteSpec) Name(name string) *RouteSpec {
+	s.name = name
+	return s
+}
+
+// Meta sets a metadata value for the route returned by Handler.
+func (s *RouteSpec) Meta(key string, value interface{}) *RouteSpec {
+	if s.meta == nil {
+		s.meta = map[string]interface{}{}
+	}
+	s.meta[key] = value
+	return s
+}
+
+// Handler returns a named route with the given handler, matching the route
+// definition. If the definition was created calling Router.NewRoute, the
+// route is also registered in the router. It returns the error returned by
+// Err, if any.
+func (s *RouteSpec) Handler(h http.Handler) (*Route, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	route := &Route{Name: s.name, Matcher: s, Handler: h, Metadata: s.meta}
+	if s.router != nil {
+		s.router.add(route)
+	}
+	return route, nil
+}
+
+// HandlerFunc calls Handler with a handler function.
+func (s *RouteSpec) HandlerFunc(f func(http.ResponseWriter, *http.Request)) (*Route, error) {
+	return s.Handler(http.HandlerFunc(f))
+}
+
+// Hook installs a hook that is called when the route is matched or built.
+func (s *RouteSpec) Hook(h Hook) *RouteSpec {
+	s.hook = h
+	return s
+}
+
+// Err returns the first error found while defining the route.
+func (s *RouteSpec) Err() error {
+	return s.err
+}
+
+// Matcher returns the composed matcher for the route.
+func (s *RouteSpec) Matcher() All {
+	return s.all
+}
+
+func (s *RouteSpec) Match(r *http.Request) bool {
+	return s.MatchInfo(NewRequestInfo(r))
+}
+
+func (s *RouteSpec) MatchInfo(info *RequestInfo) bool {
+	if s.hook == nil {
+		return s.err == nil && s.all.MatchInfo(info)
+	}
+	r := info.Request
+	s.hook.OnMatchStart(s, r)
+	start := time.Now()
+	matched := s.err == nil && s.all.MatchInfo(info)
+	s.hook.OnMatchEnd(s, r, matched, time.Since(start))
+	return matched
+}
+
+func (s *RouteSpec) String() string {
+	return s.all.String()
+}
+
+// Extract returns the variables extracted by all matchers in the route.
+func (s *RouteSpec) Extract(result *Result, r *http.Request) {
+	for _, m := range s.all {
+		if e, ok := m.(Extractor); ok {
+			e.Extract(result, r)
+		}
+	}
+	if h, ok := s.hook.(ExtractHook); ok {
+		h.OnExtract(s, r, result)
+	}
+}
+
+// Build builds a URL using the given positional and named variables, calling
+// all builders in the route.
+func (s *RouteSpec) Build(u *url.URL, values url.Values) error {
+	err := s.build(u, values)
+	if s.hook != nil {
+		s.hook.OnBuild(s, u, err)
+	}
+	return err
+}
+
+// build builds a URL calling all builders in the route.
+func (s *RouteSpec) build(u *url.URL, values url.Values) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, m := range s.all {
+		if b, ok := m.(Builder); ok {
+			if err := b.Build(u, values); err != nil {
+				return err
+			}
+		}
+	}
+	if len(s.schemes) != 0 && u.Host != "" {
+		u.Scheme = s.schemes[0]
+	}
+	return nil
+}
+
+// fail records an error, keeping the first one.
+func (s *RouteSpec) fail(err error) *RouteSpec {
+	if s.err == nil {
+		s.err = err
+	}
+	return s
+}
+
+// compile rebuilds the composed matcher.
+func (s *RouteSpec) compile() *RouteSpec {
+	var all All
+	if s.schemes != nil {
+		all = append(all, s.schemes)
+	}
+	if s.host != nil {
+		all = append(all, s.host)
+	}
+	if s.methods != nil {
+		all = append(all, s.methods)
+	}
+	if s.hasPath {
+		m, err := NewGorillaPath(s.prefix+s.path, false, s.opts...)
+		if err != nil {
+			return s.fail(err)
+		}
+		all = append(all, m)
+	} else if s.prefix != "" {
+		m, err := NewGorillaPathPrefix(s.prefix, s.opts...)
+		if err != nil {
+			return s.fail(err)
+		}
+		all = append(all, m)
+	}
+	if s.headers != nil {
+		all = append(all, s.headers)
+	}
+	if len(s.queries) != 0 {
+		all = append(all, s.queries)
+	}
+	all = append(all, s.vars...)
+	s.all = all
+	return s
+}
+
+// mapFromPairs converts a list of key/value pairs to a map.
+func mapFromPairs(pairs []string) (map[string]string, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("Number of parameters must be multiple of 2, "+
+			"got %v", pairs)
+	}
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		m[pairs[i]] = pairs[i+1]
+	}
+	return m, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRouteSpec(t *testing.T) {
+	spec := NewRouteSpec().
+		Schemes("https").
+		Host("{tenant}.example.com").
+		PathPrefix("/api").
+		Path("/users/{id:[0-9]+}").
+		Methods("GET", "PUT")
+	if err := spec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	type test struct {
+		method string
+		rURL   string
+		expect bool
+	}
+	tests := []test{
+		{"GET", "https://acme.example.com/api/users/42", true},
+		{"PUT", "https://acme.example.com/api/users/42", true},
+		{"POST", "https://acme.example.com/api/users/42", false},
+		{"GET", "http://acme.example.com/api/users/42", false},
+		{"GET", "https://acme.example.com/users/42", false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, v.rURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, "RouteSpec", spec, r, v.expect)
+	}
+	r, err := http.NewRequest("GET", "https://acme.example.com/api/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := Result{}
+	spec.Extract(&result, r)
+	expect := url.Values{"tenant": {"acme"}, "id": {"42"}}
+	if !equalValues(expect, result.Values) {
+		t.Errorf("expected %v, got %v", expect, result.Values)
+	}
+	u := url.URL{}
+	if err := spec.Build(&u, result.Values); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "https://acme.example.com/api/users/42" {
+		t.Errorf("expected %q, got %q", "https://acme.example.com/api/users/42", s)
+	}
+	if err := NewRouteSpec().Path("/{id").Err(); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
+
+func TestRouteSpecQueries(t *testing.T) {
+	spec := NewRouteSpec().Path("/search").Queries("q", "", "page", "{page:[0-9]+}")
+	if err := spec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/search?q=go&page=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "RouteSpec", spec, r, true)
+	result := Result{}
+	spec.Extract(&result, r)
+	expect := url.Values{"page": {"3"}}
+	if !equalValues(expect, result.Values) {
+		t.Errorf("expected %v, got %v", expect, result.Values)
+	}
+	u := url.URL{}
+	if err := spec.Build(&u, url.Values{"page": {"4"}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "/search?page=4" {
+		t.Errorf("expected %q, got %q", "/search?page=4", s)
+	}
+	r, err = http.NewRequest("GET", "http://domain.com/search?q=go&page=a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "RouteSpec", spec, r, false)
+}
+
+func TestRouteSpecOptions(t *testing.T) {
+	spec := NewRouteSpec().Options(WithEncoding()).
+		PathPrefix("/files").Path("/{name}")
+	if err := spec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/files/a%2Fb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "RouteSpec", spec, r, true)
+	result := Result{}
+	spec.Extract(&result, r)
+	if v := result.Values.Get("name"); v != "a%2Fb" {
+		t.Errorf("expected %q, got %q", "a%2Fb", v)
+	}
+	u := &url.URL{}
+	if err := spec.Build(u, result.Values); err != nil {
+		t.Fatal(err)
+	}
+	if u.EscapedPath() != "/files/a%2Fb" {
+		t.Errorf("expected %q, got %q", "/files/a%2Fb", u.EscapedPath())
+	}
+}
+
+func TestRouteSpecHandler(t *testing.T) {
+	router := NewRouter()
+	h := http.NotFoundHandler()
+	route, err := router.NewRoute().
+		Name("user").
+		Host("{sub}.example.com").
+		PathPrefix("/api").
+		Path("/users/{id}").
+		Methods("GET", "POST").
+		Headers("X-Auth", "").
+		Handler(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if router.Get("user") != route || route.Handler == nil {
+		t.Fatalf("expected route to be registered")
+	}
+	r, err := http.NewRequest("POST", "http://acme.example.com/api/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("X-Auth", "token")
+	if m, result := router.Match(r); m != route || result.Values.Get("sub") != "acme" ||
+		result.Values.Get("id") != "42" {
+		t.Errorf("unexpected match %v %+v", m, result)
+	}
+	u, err := router.URL("user", url.Values{"sub": {"acme"}, "id": {"42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "http://acme.example.com/api/users/42" {
+		t.Errorf("expected %q, got %q", "http://acme.example.com/api/users/42", s)
+	}
+	if _, err := router.NewRoute().Path("/{id").Handler(h); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+	if len(router.Routes()) != 1 {
+		t.Errorf("expected invalid route not to be registered")
+	}
+	route, err = NewRouteSpec().Path("/").Handler(h)
+	if err != nil || route.Name != "" || len(router.Routes()) != 1 {
+		t.Errorf("unexpected route %v, %v", route, err)
+	}
+}
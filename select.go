@@ -0,0 +1,105 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+)
+
+// ScoreFunc scores a matcher for Select; higher scores win.
+type ScoreFunc func(Matcher) int
+
+// Select returns the index of the matcher that matches the request with the
+// highest score, or -1 if none matches. Ties are won by the first matcher,
+// so a nil score selects the first matching one.
+func Select(matchers []Matcher, r *http.Request, score ScoreFunc) int {
+	best, bestScore := -1, 0
+	for k, m := range matchers {
+		if !m.Match(r) {
+			continue
+		}
+		if score == nil {
+			return k
+		}
+		if s := score(m); best == -1 || s > bestScore {
+			best, bestScore = k, s
+		}
+	}
+	return best
+}
+
+// ScoreStaticPrefix scores a matcher by the length of the static prefix of
+// its path matcher, so that "/users/new" wins over "/users/{id}".
+func ScoreStaticPrefix(m Matcher) int {
+	return staticPrefixLen(m)
+}
+
+// ScoreSpecificity scores a matcher by how specific it is: static paths
+// first, then by the number of matchers it is composed of, like a host and
+// a method, and then by the static prefix length.
+func ScoreSpecificity(m Matcher) int {
+	var static bool
+	var count int
+	walkMatchers(m, func(m Matcher) {
+		switch m.(type) {
+		case All, One, Not, *RouteSpec, *Hooked, *Route, *Mount:
+			return
+		case Path, PathFold:
+			static = true
+		}
+		count++
+	})
+	score := count<<16 + staticPrefixLen(m)
+	if static {
+		score += 1 << 30
+	}
+	return score
+}
+
+// staticPrefixLen returns the length of the static prefix of the first path
+// matcher found in the matcher.
+func staticPrefixLen(m Matcher) int {
+	n := -1
+	walkMatchers(m, func(m Matcher) {
+		if n != -1 {
+			return
+		}
+		switch v := m.(type) {
+		case Path:
+			n = len(v)
+		case PathRedirect:
+			n = len(v)
+		case PathRedirectCode:
+			n = len(v.PathRedirect)
+		case PathPrefix:
+			n = len(v)
+		case PathFold:
+			n = len(v)
+		case PathPrefixFold:
+			n = len(v)
+		case *GorillaPath:
+			n = gorillaPrefixLen(v.pattern)
+		case *GorillaPathPrefix:
+			n = gorillaPrefixLen(v.pattern)
+		case *RegexpPath:
+			prefix, _ := v.compiled.LiteralPrefix()
+			n = len(prefix)
+		}
+	})
+	if n == -1 {
+		return 0
+	}
+	return n
+}
+
+// gorillaPrefixLen returns the length of the literal prefix of a Gorilla
+// pattern.
+func gorillaPrefixLen(pattern string) int {
+	segments, err := ParseGorillaTemplate(pattern)
+	if err != nil || len(segments) == 0 || segments[0].Kind != LiteralSegment {
+		return 0
+	}
+	return len(segments[0].Literal)
+}
@@ -0,0 +1,45 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	matchers := []Matcher{
+		NewPathPrefix("/"),
+		MustNewGorillaPath("/users/{id}", false),
+		MustNewGorillaPath("/users/new", false),
+		NewAll([]Matcher{NewMethod([]string{"POST"}), MustNewGorillaPath("/users/{id}", false)}),
+		NewPath("/users/new"),
+	}
+	tests := []struct {
+		method string
+		path   string
+		score  ScoreFunc
+		expect int
+	}{
+		{"GET", "/users/new", nil, 0},
+		{"GET", "/users/new", ScoreStaticPrefix, 2},
+		{"GET", "/users/new", ScoreSpecificity, 4},
+		{"POST", "/users/42", ScoreSpecificity, 3},
+		{"GET", "/users/42", ScoreStaticPrefix, 1},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest(v.method, "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i := Select(matchers, r, v.score); i != v.expect {
+			t.Errorf("%s %s: expected %d, got %d", v.method, v.path, v.expect, i)
+		}
+	}
+	r, _ := http.NewRequest("GET", "http://domain.com/", nil)
+	if i := Select(matchers[1:4], r, ScoreSpecificity); i != -1 {
+		t.Errorf("expected -1, got %d", i)
+	}
+}
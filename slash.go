@@ -0,0 +1,165 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SlashPolicy defines how a path matcher treats the trailing slash of the
+// request path. It is set using WithSlashPolicy, or Router.SlashPolicy.
+type SlashPolicy int
+
+const (
+	// SlashStrict matches only paths with the same trailing slash as the
+	// pattern. This is the default.
+	SlashStrict SlashPolicy = iota
+	// SlashRedirectToSlash matches paths with or without the trailing
+	// slash, redirects to the path with it, and builds paths with it.
+	SlashRedirectToSlash
+	// SlashRedirectToNoSlash matches paths with or without the trailing
+	// slash, redirects to the path without it, and builds paths without it.
+	SlashRedirectToNoSlash
+	// SlashIgnore matches paths with or without the trailing slash, without
+	// redirecting, and builds paths like the pattern.
+	SlashIgnore
+)
+
+func (p SlashPolicy) String() string {
+	switch p {
+	case SlashStrict:
+		return "Strict"
+	case SlashRedirectToSlash:
+		return "RedirectToSlash"
+	case SlashRedirectToNoSlash:
+		return "RedirectToNoSlash"
+	case SlashIgnore:
+		return "Ignore"
+	}
+	return fmt.Sprintf("SlashPolicy(%d)", int(p))
+}
+
+// patternSlashPolicy returns the policy that redirects to the trailing slash
+// of the pattern, which is what WithStrictSlash does.
+func patternSlashPolicy(pattern string) SlashPolicy {
+	if strings.HasSuffix(pattern, "/") {
+		return SlashRedirectToSlash
+	}
+	return SlashRedirectToNoSlash
+}
+
+// matchPath returns whether the path matches the pattern path under the
+// policy.
+func (p SlashPolicy) matchPath(path, pattern string) bool {
+	if p == SlashStrict {
+		return path == pattern
+	}
+	return strings.TrimSuffix(path, "/") == strings.TrimSuffix(pattern, "/")
+}
+
+// redirect returns a handler that redirects to the canonical path of the
+// request, or nil if it is already canonical.
+func (p SlashPolicy) redirect(r *http.Request, stripQuery bool, code int) http.Handler {
+	slash := strings.HasSuffix(r.URL.Path, "/")
+	switch {
+	case p == SlashRedirectToSlash && !slash:
+	case p == SlashRedirectToNoSlash && slash && r.URL.Path != "/":
+	default:
+		return nil
+	}
+	u := withTrailingSlash(r.URL, !slash)
+	if stripQuery {
+		u.RawQuery = ""
+		u.ForceQuery = false
+	}
+	return http.RedirectHandler(u.String(), code)
+}
+
+// canonical returns the canonical form of a path built for the pattern.
+func (p SlashPolicy) canonical(path, pattern string) string {
+	slash := strings.HasSuffix(path, "/")
+	switch {
+	case p == SlashRedirectToSlash && !slash:
+		return path + "/"
+	case p == SlashRedirectToNoSlash && slash && len(path) > 1:
+		return path[:len(path)-1]
+	case p == SlashIgnore && !slash && strings.HasSuffix(pattern, "/"):
+		return path + "/"
+	}
+	return path
+}
+
+// alternate returns the path with the trailing slash added or removed, if
+// the policy allows to match it instead of the given one.
+func (p SlashPolicy) alternate(path string) (string, bool) {
+	slash := strings.HasSuffix(path, "/")
+	switch {
+	case p == SlashStrict, path == "/":
+		return "", false
+	case slash && p != SlashRedirectToSlash:
+		return path[:len(path)-1], true
+	case !slash && p != SlashRedirectToNoSlash:
+		return path + "/", true
+	}
+	return "", false
+}
+
+// withTrailingSlash returns a copy of the URL with the trailing slash of the
+// path added or removed.
+func withTrailingSlash(u *url.URL, slash bool) *url.URL {
+	v := *u
+	if slash {
+		v.Path += "/"
+		if v.RawPath != "" {
+			v.RawPath += "/"
+		}
+	} else {
+		v.Path = strings.TrimSuffix(v.Path, "/")
+		v.RawPath = strings.TrimSuffix(v.RawPath, "/")
+	}
+	return &v
+}
+
+// SlashPath ------------------------------------------------------------------
+
+// NewSlashPath returns a static URL path matcher using the given trailing
+// slash policy.
+func NewSlashPath(path string, policy SlashPolicy) SlashPath {
+	return SlashPath{Path: NewPath(path), Policy: policy}
+}
+
+// SlashPath matches a static URL path, treating the trailing slash according
+// to its policy. It redirects to the canonical path if the policy says so,
+// using Code, or 301 if it is zero, and builds the canonical path.
+type SlashPath struct {
+	Path   Path
+	Policy SlashPolicy
+	Code   int
+}
+
+func (m SlashPath) Match(r *http.Request) bool {
+	return m.Policy.matchPath(r.URL.Path, string(m.Path))
+}
+
+func (m SlashPath) String() string {
+	return fmt.Sprintf("SlashPath(%q, %v, %d)", string(m.Path), m.Policy, m.Code)
+}
+
+// Extract sets a redirect to the canonical path, if needed.
+func (m SlashPath) Extract(result *Result, r *http.Request) {
+	if result.Handler == nil {
+		code := redirectCode(m.Code)
+		result.Handler = m.Policy.redirect(r, false, code)
+	}
+}
+
+// Build writes the canonical path to the given URL.
+func (m SlashPath) Build(u *url.URL, values url.Values) error {
+	u.Path = m.Policy.canonical(string(m.Path), string(m.Path))
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// slashTest is a test for a path matcher using a slash policy.
+type slashTest struct {
+	policy   SlashPolicy
+	pattern  string
+	path     string
+	match    bool
+	redirect string
+	build    string
+}
+
+func TestSlashPolicy(t *testing.T) {
+	tests := []slashTest{
+		{SlashStrict, "/users/{id}", "/users/1", true, "", "/users/1"},
+		{SlashStrict, "/users/{id}", "/users/1/", false, "", ""},
+		{SlashRedirectToSlash, "/users/{id}", "/users/1", true, "/users/1/", "/users/1/"},
+		{SlashRedirectToSlash, "/users/{id}", "/users/1/", true, "", "/users/1/"},
+		{SlashRedirectToNoSlash, "/users/{id}/", "/users/1/", true, "/users/1", "/users/1"},
+		{SlashRedirectToNoSlash, "/users/{id}/", "/users/1", true, "", "/users/1"},
+		{SlashIgnore, "/users/{id}/", "/users/1", true, "", "/users/1/"},
+		{SlashIgnore, "/users/{id}", "/users/1/", true, "", "/users/1"},
+	}
+	for _, v := range tests {
+		testSlashPolicy(t, MustNewGorillaPath(v.pattern, false, WithSlashPolicy(v.policy)), v)
+	}
+}
+
+func TestSlashPath(t *testing.T) {
+	tests := []slashTest{
+		{SlashStrict, "/about", "/about/", false, "", ""},
+		{SlashRedirectToSlash, "/about", "/about", true, "/about/", "/about/"},
+		{SlashRedirectToNoSlash, "/about/", "/about/", true, "/about", "/about"},
+		{SlashRedirectToNoSlash, "/", "/", true, "", "/"},
+		{SlashIgnore, "/about/", "/about", true, "", "/about/"},
+	}
+	for _, v := range tests {
+		testSlashPolicy(t, NewSlashPath(v.pattern, v.policy), v)
+	}
+}
+
+func testSlashPolicy(t *testing.T, m Matcher, v slashTest) {
+	r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, v.policy.String(), m, r, v.match)
+	if !v.match {
+		return
+	}
+	result := Result{}
+	m.(Extractor).Extract(&result, r)
+	var redirect string
+	if result.Handler != nil {
+		w := httptest.NewRecorder()
+		result.Handler.ServeHTTP(w, r)
+		redirect = strings.TrimPrefix(w.Header().Get("Location"), "http://domain.com")
+	}
+	u := &url.URL{}
+	if err := m.(Builder).Build(u, url.Values{"id": {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if redirect != v.redirect || u.Path != v.build {
+		t.Errorf("%v %s %s: expected redirect %q and build %q, got %q and %q",
+			v.policy, v.pattern, v.path, v.redirect, v.build, redirect, u.Path)
+	}
+}
+
+func TestStrictSlashBuild(t *testing.T) {
+	m := MustNewGorillaPath("/users/{id}/", true)
+	u := &url.URL{}
+	if err := m.Build(u, url.Values{"id": {"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/users/1/" {
+		t.Errorf("expected %q, got %q", "/users/1/", u.Path)
+	}
+}
+
+func TestRouterSlashPolicy(t *testing.T) {
+	router := NewRouter()
+	router.HandleFunc("users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	}, NewPath("/users/"))
+	router.HandleFunc("about", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("about"))
+	}, NewPath("/about"))
+	tests := []struct {
+		policy   SlashPolicy
+		path     string
+		code     int
+		location string
+	}{
+		{SlashStrict, "/users", http.StatusNotFound, ""},
+		{SlashRedirectToSlash, "/users", http.StatusMovedPermanently, "http://domain.com/users/?q=1"},
+		{SlashRedirectToSlash, "/about/", http.StatusNotFound, ""},
+		{SlashRedirectToNoSlash, "/about/", http.StatusMovedPermanently, "http://domain.com/about?q=1"},
+		{SlashRedirectToNoSlash, "/users", http.StatusNotFound, ""},
+		{SlashIgnore, "/users", http.StatusOK, ""},
+		{SlashIgnore, "/about/", http.StatusOK, ""},
+	}
+	for _, v := range tests {
+		router.SlashPolicy = v.policy
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path+"?q=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != v.code || w.Header().Get("Location") != v.location {
+			t.Errorf("%v %s: expected %d %q, got %d %q", v.policy, v.path, v.code,
+				v.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
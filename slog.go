@@ -0,0 +1,86 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+package reverse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// NewSlogHook returns a hook that logs match decisions to the given logger.
+// It is enabled by default.
+//
+// Install it using RouteSpec.Hook to log the outcome of whole routes, or
+// using Instrument to also log which matcher in a composite one failed.
+func NewSlogHook(logger *slog.Logger, level slog.Level) *SlogHook {
+	h := &SlogHook{logger: logger, level: level}
+	h.enabled.Store(true)
+	return h
+}
+
+// SlogHook is a Hook that emits structured log records using log/slog.
+//
+// Logging can be toggled at runtime using SetEnabled.
+type SlogHook struct {
+	logger  *slog.Logger
+	level   slog.Level
+	enabled atomic.Bool
+}
+
+// SetEnabled enables or disables logging. It is safe for concurrent use.
+func (h *SlogHook) SetEnabled(enabled bool) {
+	h.enabled.Store(enabled)
+}
+
+// Enabled returns whether logging is enabled.
+func (h *SlogHook) Enabled() bool {
+	return h.enabled.Load() && h.logger.Enabled(context.Background(), h.level)
+}
+
+func (h *SlogHook) OnMatchStart(m Matcher, r *http.Request) {
+}
+
+func (h *SlogHook) OnMatchEnd(m Matcher, r *http.Request, matched bool, elapsed time.Duration) {
+	if !h.Enabled() {
+		return
+	}
+	h.logger.LogAttrs(r.Context(), h.level, "reverse: match",
+		slog.String("matcher", fmt.Sprint(m)),
+		slog.Bool("matched", matched),
+		slog.Duration("elapsed", elapsed),
+		slog.String("method", r.Method),
+		slog.String("url", r.URL.String()))
+}
+
+func (h *SlogHook) OnExtract(e Extractor, r *http.Request, result *Result) {
+	if !h.Enabled() {
+		return
+	}
+	h.logger.LogAttrs(r.Context(), h.level, "reverse: extract",
+		slog.String("matcher", fmt.Sprint(e)),
+		slog.Any("values", result.Values))
+}
+
+func (h *SlogHook) OnBuild(b Builder, u *url.URL, err error) {
+	if !h.Enabled() {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("builder", fmt.Sprint(b)),
+		slog.String("url", u.String()),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	h.logger.LogAttrs(context.Background(), h.level, "reverse: build",
+		attrs...)
+}
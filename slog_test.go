@@ -0,0 +1,36 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+package reverse
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSlogHook(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHook(slog.New(slog.NewTextHandler(&buf, nil)), slog.LevelInfo)
+	m := Instrument(NewAll([]Matcher{NewPath("/a"), NewMethod([]string{"POST"})}), h)
+	r, err := http.NewRequest("GET", "http://domain.com/a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Match(r)
+	out := buf.String()
+	if !strings.Contains(out, `matcher="Method(\"POST\")" matched=false`) {
+		t.Errorf("expected failing matcher to be logged, got %q", out)
+	}
+	buf.Reset()
+	h.SetEnabled(false)
+	m.Match(r)
+	if buf.Len() != 0 {
+		t.Errorf("expected no logs when disabled, got %q", buf.String())
+	}
+}
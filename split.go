@@ -0,0 +1,96 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SplitKey is the variable name used by Split for the request bucket.
+const SplitKey = "bucket"
+
+// SplitKeyFunc returns the key used to assign a request to a bucket, or an
+// empty string if the request has none.
+type SplitKeyFunc func(*http.Request) string
+
+// SplitByCookie returns the value of the named cookie as the split key.
+func SplitByCookie(name string) SplitKeyFunc {
+	return func(r *http.Request) string {
+		if c, err := r.Cookie(name); err == nil {
+			return c.Value
+		}
+		return ""
+	}
+}
+
+// SplitByHeader returns the value of the named header as the split key.
+func SplitByHeader(name string) SplitKeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// SplitByRemoteAddr returns the client IP address as the split key.
+func SplitByRemoteAddr() SplitKeyFunc {
+	return func(r *http.Request) string {
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			return host
+		}
+		return r.RemoteAddr
+	}
+}
+
+// NewSplit returns a matcher for the given percentage of requests, bucketed
+// by the given key. The name identifies the split, so that different splits
+// using the same key select different requests.
+func NewSplit(name string, percent int, key SplitKeyFunc) *Split {
+	return &Split{Name: name, Percent: percent, Key: key}
+}
+
+// Split matches a percentage of requests, for canary releases or A/B tests.
+// Requests are assigned to one of 100 buckets hashing the split name and
+// key, so the same key always gets the same bucket; buckets below Percent
+// match. Requests without a key never match.
+type Split struct {
+	Name    string
+	Percent int
+	Key     SplitKeyFunc
+}
+
+func (m *Split) Match(r *http.Request) bool {
+	bucket, ok := m.Bucket(r)
+	return ok && bucket < m.Percent
+}
+
+func (m *Split) String() string {
+	return fmt.Sprintf("Split(%q, %d)", m.Name, m.Percent)
+}
+
+// Extract returns the request bucket using SplitKey.
+func (m *Split) Extract(result *Result, r *http.Request) {
+	if bucket, ok := m.Bucket(r); ok {
+		result.Values = mergeValues(result.Values,
+			url.Values{SplitKey: {strconv.Itoa(bucket)}})
+	}
+}
+
+// Bucket returns the bucket of the request, from 0 to 99. It returns false
+// if the request has no key.
+func (m *Split) Bucket(r *http.Request) (int, bool) {
+	key := m.Key(r)
+	if key == "" {
+		return 0, false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(m.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100), true
+}
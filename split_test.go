@@ -0,0 +1,51 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	m := NewSplit("canary", 20, SplitByCookie("session"))
+	var matched int
+	for i := 0; i < 1000; i++ {
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.AddCookie(&http.Cookie{Name: "session", Value: fmt.Sprint(i)})
+		bucket, ok := m.Bucket(r)
+		if !ok || bucket < 0 || bucket >= 100 {
+			t.Fatalf("unexpected bucket %d, %v", bucket, ok)
+		}
+		if m.Match(r) != (bucket < 20) || m.Match(r) != m.Match(r) {
+			t.Fatalf("inconsistent match for bucket %d", bucket)
+		}
+		if m.Match(r) {
+			matched++
+		}
+		result := &Result{}
+		m.Extract(result, r)
+		if v := result.Values.Get(SplitKey); v != fmt.Sprint(bucket) {
+			t.Fatalf("expected bucket %d, got %q", bucket, v)
+		}
+	}
+	if matched < 150 || matched > 250 {
+		t.Errorf("expected about 200 matches, got %d", matched)
+	}
+
+	r, _ := http.NewRequest("GET", "http://domain.com/", nil)
+	testMatcher(t, "Split", NewSplit("all", 100, SplitByHeader("X-User")), r, false)
+	r.Header.Set("X-User", "joe")
+	testMatcher(t, "Split", NewSplit("all", 100, SplitByHeader("X-User")), r, true)
+	testMatcher(t, "Split", NewSplit("none", 0, SplitByHeader("X-User")), r, false)
+	r.RemoteAddr = "10.0.0.1:1234"
+	if key := SplitByRemoteAddr()(r); key != "10.0.0.1" {
+		t.Errorf("expected 10.0.0.1, got %q", key)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SyntaxCompiler converts a pattern written in an alternative syntax to a
+// regexp pattern, whose named groups become the route variables. The host
+// argument tells if the pattern is for a URL host instead of a path.
+type SyntaxCompiler func(pattern string, host bool) (string, error)
+
+var (
+	syntaxesMu sync.RWMutex
+	syntaxes   = map[string]SyntaxCompiler{
+		"gorilla": func(pattern string, host bool) (string, error) {
+			return gorillaPattern(pattern, "", host, false, false)
+		},
+	}
+)
+
+// RegisterSyntax registers a pattern syntax under the given name. It
+// replaces any syntax previously registered with the name.
+//
+// NewRegexpHost and NewRegexpPath compile patterns using a registered
+// syntax when it is selected with WithSyntax; for example, after
+// registering a "rails" syntax:
+//
+//	m, err := reverse.NewRegexpPath("/articles/:id", reverse.WithSyntax("rails"))
+//
+// The "gorilla" syntax is registered by default.
+func RegisterSyntax(name string, compiler SyntaxCompiler) {
+	syntaxesMu.Lock()
+	defer syntaxesMu.Unlock()
+	syntaxes[name] = compiler
+}
+
+// WithSyntax makes NewRegexpHost and NewRegexpPath compile the pattern using
+// the syntax registered with the given name; see RegisterSyntax.
+func WithSyntax(name string) Option {
+	return func(o *options) {
+		o.syntax = name
+	}
+}
+
+// compileSyntax converts a pattern written in the syntax selected by the
+// options to a regexp pattern. Patterns are returned unchanged if no syntax
+// is selected.
+func (o options) compileSyntax(pattern string, host bool) (string, error) {
+	if o.syntax == "" {
+		return pattern, nil
+	}
+	syntaxesMu.RLock()
+	compiler, ok := syntaxes[o.syntax]
+	syntaxesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("Unknown pattern syntax %q", o.syntax)
+	}
+	return compiler(pattern, host)
+}
@@ -0,0 +1,57 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegisterSyntax(t *testing.T) {
+	RegisterSyntax("rails", func(pattern string, host bool) (string, error) {
+		parts := strings.Split(pattern, "/")
+		for k, v := range parts {
+			if strings.HasPrefix(v, ":") {
+				parts[k] = "(?P<" + v[1:] + ">[^/]+)"
+			} else {
+				parts[k] = regexp.QuoteMeta(v)
+			}
+		}
+		return "^" + strings.Join(parts, "/") + "$", nil
+	})
+	t.Cleanup(func() {
+		syntaxesMu.Lock()
+		defer syntaxesMu.Unlock()
+		delete(syntaxes, "rails")
+	})
+	for syntax, pattern := range map[string]string{"rails": "/articles/:id", "gorilla": "/articles/{id}"} {
+		m, err := NewRegexpPath(pattern, WithSyntax(syntax))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := http.NewRequest("GET", "http://domain.com/articles/42", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, pattern, m, r, true)
+		u := url.URL{}
+		if err := m.Build(&u, url.Values{"id": {"7"}}); err != nil {
+			t.Fatal(err)
+		}
+		if u.Path != "/articles/7" {
+			t.Errorf("%s: expected %q, got %q", pattern, "/articles/7", u.Path)
+		}
+	}
+	// Without WithSyntax, a prefix is part of the regexp.
+	if m := MustNewRegexpPath(`^rails:/a$`); !m.MatchString("rails:/a") {
+		t.Errorf("expected the pattern to be used as a regexp")
+	}
+	if _, err := NewRegexpPath("/a", WithSyntax("unknown")); err == nil {
+		t.Errorf("expected error for unknown syntax")
+	}
+}
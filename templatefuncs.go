@@ -0,0 +1,62 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/url"
+	texttemplate "text/template"
+)
+
+// TemplateErrorMode sets how the template functions report errors.
+type TemplateErrorMode int
+
+const (
+	// TemplateErrorReturn returns the error, which stops the template
+	// execution.
+	TemplateErrorReturn TemplateErrorMode = iota
+	// TemplateErrorEmpty ignores the error and writes an empty string.
+	TemplateErrorEmpty
+)
+
+// TemplateFuncs returns template functions to build the URLs of the router
+// routes. The "url" function takes a route name followed by key/value pairs,
+// formatted with fmt.Sprint:
+//
+//	tpl := template.Must(template.New("").Funcs(reverse.TemplateFuncs(router,
+//		reverse.TemplateErrorReturn)).Parse(`{{url "user" "id" .ID}}`))
+//
+// For html/template, convert the result with html/template.FuncMap.
+func TemplateFuncs(r *Router, mode TemplateErrorMode) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"url": func(name string, pairs ...interface{}) (string, error) {
+			u, err := templateURL(r, name, pairs)
+			if err == nil {
+				return u, nil
+			}
+			if mode == TemplateErrorEmpty {
+				return "", nil
+			}
+			return "", err
+		},
+	}
+}
+
+// templateURL builds the URL of a route using key/value pairs.
+func templateURL(r *Router, name string, pairs []interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("Number of parameters must be multiple of 2, "+
+			"got %v", pairs)
+	}
+	values := url.Values{}
+	for i := 0; i < len(pairs); i += 2 {
+		values.Add(fmt.Sprint(pairs[i]), fmt.Sprint(pairs[i+1]))
+	}
+	u, err := r.URL(name, values)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
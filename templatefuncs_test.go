@@ -0,0 +1,51 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	htmltemplate "html/template"
+	"net/http"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	router := NewRouter()
+	router.Handle("user", http.NotFoundHandler(),
+		MustNewGorillaPath("/users/{id:[0-9]+}", false))
+	tests := []struct {
+		mode   TemplateErrorMode
+		text   string
+		result string
+		err    bool
+	}{
+		{TemplateErrorReturn, `{{url "user" "id" .}}`, "/users/42", false},
+		{TemplateErrorReturn, `{{url "user" "id" "x"}}`, "", true},
+		{TemplateErrorReturn, `{{url "missing"}}`, "", true},
+		{TemplateErrorReturn, `{{url "user" "id"}}`, "", true},
+		{TemplateErrorEmpty, `<{{url "missing"}}>`, "<>", false},
+	}
+	for _, v := range tests {
+		var b strings.Builder
+		tpl := texttemplate.Must(texttemplate.New("").
+			Funcs(TemplateFuncs(router, v.mode)).Parse(v.text))
+		err := tpl.Execute(&b, 42)
+		if (err != nil) != v.err || !v.err && b.String() != v.result {
+			t.Errorf("%s: expected %q (error %v), got %q (%v)", v.text, v.result,
+				v.err, b.String(), err)
+		}
+	}
+	var b strings.Builder
+	tpl := htmltemplate.Must(htmltemplate.New("").
+		Funcs(htmltemplate.FuncMap(TemplateFuncs(router, TemplateErrorReturn))).
+		Parse(`<a href="{{url "user" "id" .}}">`))
+	if err := tpl.Execute(&b, 42); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != `<a href="/users/42">` {
+		t.Errorf("expected %q, got %q", `<a href="/users/42">`, b.String())
+	}
+}
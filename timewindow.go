@@ -0,0 +1,61 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewTimeWindow returns a matcher for requests received from start, included,
+// until end, excluded. A zero time leaves that side unbounded.
+func NewTimeWindow(start, end time.Time) *TimeWindow {
+	return &TimeWindow{Start: start, End: end}
+}
+
+// NewGoLive returns a matcher for requests received from the given time.
+func NewGoLive(start time.Time) *TimeWindow {
+	return &TimeWindow{Start: start}
+}
+
+// TimeWindow matches requests received during a time window, for example to
+// switch routes at a scheduled time. Several windows can be combined with
+// One.
+//
+// Now returns the current time; if nil, time.Now is used. Tests can set it
+// to a fixed clock.
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+	Now   func() time.Time
+}
+
+func (m *TimeWindow) Match(r *http.Request) bool {
+	now := m.now()
+	if !m.Start.IsZero() && now.Before(m.Start) {
+		return false
+	}
+	return m.End.IsZero() || now.Before(m.End)
+}
+
+func (m *TimeWindow) String() string {
+	return "TimeWindow(" + formatTime(m.Start) + ", " + formatTime(m.End) + ")"
+}
+
+// now returns the current time.
+func (m *TimeWindow) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// formatTime formats a time in RFC 3339 format, or "-" if it is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTimeWindow(t *testing.T) {
+	const name = "TimeWindow"
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	tests := []struct {
+		matcher *TimeWindow
+		now     time.Time
+		expect  bool
+	}{
+		{NewTimeWindow(start, end), start.Add(-time.Second), false},
+		{NewTimeWindow(start, end), start, true},
+		{NewTimeWindow(start, end), end.Add(-time.Second), true},
+		{NewTimeWindow(start, end), end, false},
+		{NewGoLive(start), start.Add(-time.Second), false},
+		{NewGoLive(start), start.Add(24 * time.Hour), true},
+		{NewTimeWindow(time.Time{}, end), start.Add(-24 * time.Hour), true},
+	}
+	r, err := http.NewRequest("GET", "http://domain.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range tests {
+		now := v.now
+		v.matcher.Now = func() time.Time { return now }
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+	expect := "TimeWindow(2024-03-01T09:00:00Z, -)"
+	if s := NewGoLive(start).String(); s != expect {
+		t.Errorf("expected %s, got %s", expect, s)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// NewTLS returns a matcher for requests received over TLS.
+func NewTLS() TLS {
+	return TLS{}
+}
+
+// TLS matches requests received over TLS, checking r.TLS regardless of the
+// URL scheme. MinVersion, if not zero, is the minimum TLS version, like
+// tls.VersionTLS12; ClientCert requires a client certificate.
+type TLS struct {
+	MinVersion uint16
+	ClientCert bool
+}
+
+func (m TLS) Match(r *http.Request) bool {
+	if r.TLS == nil || r.TLS.Version < m.MinVersion {
+		return false
+	}
+	return !m.ClientCert || len(r.TLS.PeerCertificates) != 0
+}
+
+func (m TLS) String() string {
+	return fmt.Sprintf("TLS(%#x, %v)", m.MinVersion, m.ClientCert)
+}
@@ -0,0 +1,40 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+)
+
+func TestTLS(t *testing.T) {
+	const name = "TLS"
+	tls12 := &tls.ConnectionState{Version: tls.VersionTLS12}
+	tls13 := &tls.ConnectionState{Version: tls.VersionTLS13,
+		PeerCertificates: []*x509.Certificate{{}}}
+	tests := []struct {
+		matcher TLS
+		state   *tls.ConnectionState
+		expect  bool
+	}{
+		{NewTLS(), nil, false},
+		{NewTLS(), tls12, true},
+		{TLS{MinVersion: tls.VersionTLS13}, tls12, false},
+		{TLS{MinVersion: tls.VersionTLS13}, tls13, true},
+		{TLS{ClientCert: true}, tls12, false},
+		{TLS{ClientCert: true}, tls13, true},
+	}
+	for _, v := range tests {
+		// The scheme is ignored.
+		r, err := http.NewRequest("GET", "http://domain.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.TLS = v.state
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+// NewVarRegistry returns an empty registry of variable transformers.
+func NewVarRegistry() *VarRegistry {
+	return &VarRegistry{vars: map[string]*VarTransform{}}
+}
+
+// VarRegistry stores transformers for named variables, applied by the
+// matchers created with WithVarRegistry:
+//
+//	vars := reverse.NewVarRegistry()
+//	vars.Var("slug").OnExtract(strings.ToLower).OnBuild(slugify)
+//	m := reverse.MustNewGorillaPath("/posts/{slug}", false,
+//		reverse.WithVarRegistry(vars))
+//
+// Transformers must be registered before the matchers are used; the
+// registry is not safe for concurrent modification.
+type VarRegistry struct {
+	vars map[string]*VarTransform
+}
+
+// Var returns the transformers of the named variable, adding them if
+// needed.
+func (reg *VarRegistry) Var(name string) *VarTransform {
+	v, ok := reg.vars[name]
+	if !ok {
+		v = &VarTransform{}
+		reg.vars[name] = v
+	}
+	return v
+}
+
+// extract applies the extract transformers of the named variable.
+func (reg *VarRegistry) extract(name, value string) string {
+	if reg == nil {
+		return value
+	}
+	if v, ok := reg.vars[name]; ok {
+		value = apply(v.extract, value)
+	}
+	return value
+}
+
+// build applies the build transformers of the named variable.
+func (reg *VarRegistry) build(name, value string) string {
+	if reg == nil {
+		return value
+	}
+	if v, ok := reg.vars[name]; ok {
+		value = apply(v.build, value)
+	}
+	return value
+}
+
+// VarTransform holds the transformers of a variable. Extract transformers
+// run on the values extracted from requests, after unescaping them; build
+// transformers run on the values used to build URLs, before validating and
+// escaping them. Each kind runs in the order it was added.
+type VarTransform struct {
+	extract []func(string) string
+	build   []func(string) string
+}
+
+// OnExtract adds a transformer for the extracted values.
+func (v *VarTransform) OnExtract(fn func(string) string) *VarTransform {
+	v.extract = append(v.extract, fn)
+	return v
+}
+
+// OnBuild adds a transformer for the values used to build URLs.
+func (v *VarTransform) OnBuild(fn func(string) string) *VarTransform {
+	v.build = append(v.build, fn)
+	return v
+}
+
+// apply calls the given transformers in order.
+func apply(fns []func(string) string, value string) string {
+	for _, fn := range fns {
+		value = fn(value)
+	}
+	return value
+}
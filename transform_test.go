@@ -0,0 +1,41 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVarRegistry(t *testing.T) {
+	vars := NewVarRegistry()
+	vars.Var("slug").OnExtract(strings.ToLower).OnBuild(strings.TrimSpace).
+		OnBuild(func(s string) string {
+			return strings.ReplaceAll(strings.ToLower(s), " ", "-")
+		})
+	for _, opt := range []Option{WithVarRegistry(vars),
+		WithRegexpCache(NewRegexpCache(0))} {
+		m := MustNewGorillaPath("/posts/{slug:[A-Za-z-]+}/{id}", false,
+			WithVarRegistry(vars), opt)
+		r, err := http.NewRequest("GET", "http://domain.com/posts/Hello-World/ID", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Result{}
+		m.Extract(&result, r)
+		if result.Values.Get("slug") != "hello-world" || result.Values.Get("id") != "ID" {
+			t.Errorf("expected transformed slug and untouched id, got %v", result.Values)
+		}
+		u := &url.URL{}
+		if err := m.Build(u, url.Values{"slug": {" Hello World "}, "id": {"1"}}); err != nil {
+			t.Fatal(err)
+		}
+		if u.Path != "/posts/hello-world/1" {
+			t.Errorf("expected %q, got %q", "/posts/hello-world/1", u.Path)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NewUpgrade returns a matcher for requests asking to upgrade the
+// connection to the given protocol.
+func NewUpgrade(protocol string) Upgrade {
+	return Upgrade(strings.ToLower(protocol))
+}
+
+// NewWebSocket returns a matcher for WebSocket handshake requests.
+func NewWebSocket() Upgrade {
+	return NewUpgrade("websocket")
+}
+
+// Upgrade matches requests asking to upgrade the connection to a protocol:
+// the Connection header must have the "upgrade" token and the Upgrade
+// header the protocol one. Tokens are compared ignoring case, and headers
+// can list several of them, like "keep-alive, Upgrade".
+type Upgrade string
+
+func (m Upgrade) Match(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") &&
+		headerHasToken(r.Header, "Upgrade", string(m))
+}
+
+func (m Upgrade) String() string {
+	return fmt.Sprintf("Upgrade(%q)", string(m))
+}
+
+// headerHasToken returns whether one of the comma-separated values of the
+// header is the token, ignoring case. Protocol versions, like "/13" in
+// "websocket/13", are ignored.
+func headerHasToken(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if i := strings.IndexByte(s, '/'); i != -1 {
+				s = s[:i]
+			}
+			if strings.EqualFold(s, token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	const name = "Upgrade"
+	tests := []struct {
+		matcher    Upgrade
+		connection []string
+		upgrade    []string
+		expect     bool
+	}{
+		{NewWebSocket(), []string{"Upgrade"}, []string{"websocket"}, true},
+		{NewWebSocket(), []string{"keep-alive, UPGRADE"}, []string{"WebSocket"}, true},
+		{NewWebSocket(), []string{"keep-alive", "upgrade"}, []string{"websocket/13"}, true},
+		{NewWebSocket(), []string{"keep-alive"}, []string{"websocket"}, false},
+		{NewWebSocket(), []string{"upgraded"}, []string{"websocket"}, false},
+		{NewWebSocket(), []string{"Upgrade"}, []string{"h2c"}, false},
+		{NewWebSocket(), nil, nil, false},
+		{NewUpgrade("H2C"), []string{"Upgrade, HTTP2-Settings"}, []string{"h2c"}, true},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com/ws", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header["Connection"] = v.connection
+		r.Header["Upgrade"] = v.upgrade
+		testMatcher(t, name, v.matcher, r, v.expect)
+	}
+}
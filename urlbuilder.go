@@ -0,0 +1,175 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NewURLBuilder returns a builder that composes the given builders; for
+// example, a host, a path and a query builder.
+func NewURLBuilder(builders ...Builder) *URLBuilder {
+	return &URLBuilder{builders: builders}
+}
+
+// URLBuilder calls a list of builders in order to build a complete URL.
+type URLBuilder struct {
+	builders []Builder
+}
+
+// Build calls all builders with the given URL and values. It stops at the
+// first error.
+//
+// The values are modified in place, and only the unused ones are left.
+func (b *URLBuilder) Build(u *url.URL, values url.Values) error {
+	for _, v := range b.builders {
+		if err := v.Build(u, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *URLBuilder) String() string {
+	parts := make([]string, len(b.builders))
+	for k, v := range b.builders {
+		parts[k] = fmt.Sprint(v)
+	}
+	return "URLBuilder(" + strings.Join(parts, ", ") + ")"
+}
+
+// URL builds a new URL using the given values, which are not modified.
+//
+// Named values not used by any builder are added to the URL query, like
+// gorilla/mux does.
+func (b *URLBuilder) URL(values url.Values) (*url.URL, error) {
+	values = cloneValues(values)
+	u := &url.URL{}
+	if err := BuildWith(b, u, values, BuildOptions{AppendQuery: true}); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// NewBaseURL returns a builder that makes the URLs built by b absolute
+// using a base URL, like "https://cdn.example.com/app".
+func NewBaseURL(base string, b Builder) (*BaseURL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Base URL %q must be absolute", base)
+	}
+	return &BaseURL{Scheme: u.Scheme, Host: u.Host, Path: u.Path,
+		Builder: b}, nil
+}
+
+// BaseURL builds absolute URLs, for example for emails or sitemaps, even
+// when the builder only defines a path.
+//
+// The scheme, if not empty, replaces the one set by the builder. The host is
+// used when the builder sets none, and the path is prepended to the built
+// one.
+type BaseURL struct {
+	Scheme  string
+	Host    string
+	Path    string
+	Builder Builder
+}
+
+// Build calls the builder and applies the base URL.
+//
+// The values are modified in place, and only the unused ones are left.
+func (b *BaseURL) Build(u *url.URL, values url.Values) error {
+	if err := b.Builder.Build(u, values); err != nil {
+		return err
+	}
+	if b.Scheme != "" {
+		u.Scheme = b.Scheme
+	}
+	if u.Host == "" {
+		u.Host = b.Host
+	}
+	if b.Path != "" {
+		u.Path = joinPath(b.Path, u.Path)
+	}
+	return nil
+}
+
+func (b *BaseURL) String() string {
+	base := url.URL{Scheme: b.Scheme, Host: b.Host, Path: b.Path}
+	return fmt.Sprintf("BaseURL(%q, %v)", base.String(), b.Builder)
+}
+
+// URL builds a new absolute URL using the given values, which are not
+// modified.
+func (b *BaseURL) URL(values url.Values) (*url.URL, error) {
+	u := &url.URL{}
+	if err := b.Build(u, cloneValues(values)); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// BuildOptions configures BuildWith.
+type BuildOptions struct {
+	// AppendQuery adds the named values not used by the builder to the URL
+	// query, instead of dropping them.
+	AppendQuery bool
+	// Fragment, if not empty, is set as the URL fragment, without the "#".
+	Fragment string
+}
+
+// BuildWith calls the builder with the given URL and values, applying the
+// build options.
+func BuildWith(b Builder, u *url.URL, values url.Values, opts BuildOptions) error {
+	if err := b.Build(u, values); err != nil {
+		return err
+	}
+	if opts.AppendQuery {
+		appendQuery(u, values)
+	}
+	if opts.Fragment != "" {
+		u.Fragment = opts.Fragment
+	}
+	return nil
+}
+
+// Fragment is a builder that sets a static URL fragment, without the "#",
+// for links to a part of a page. It can be composed with other builders
+// using NewURLBuilder.
+type Fragment string
+
+// Build writes the fragment to the given URL.
+func (b Fragment) Build(u *url.URL, values url.Values) error {
+	u.Fragment = string(b)
+	return nil
+}
+
+func (b Fragment) String() string {
+	return fmt.Sprintf("Fragment(%q)", string(b))
+}
+
+// appendQuery adds the unused named values to the URL query.
+func appendQuery(u *url.URL, values url.Values) {
+	var query url.Values
+	for k, v := range values {
+		if k == "" || len(v) == 0 {
+			continue
+		}
+		if query == nil {
+			query = u.Query()
+		}
+		for _, s := range v {
+			query.Add(k, s)
+		}
+	}
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+}
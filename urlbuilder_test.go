@@ -0,0 +1,102 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURLBuilder(t *testing.T) {
+	b := NewURLBuilder(
+		MustNewGorillaHost("{tenant}.domain.com"),
+		MustNewGorillaPath("/users/{id}", false),
+		MustNewGorillaQuery("tab", "{tab}"),
+	)
+	values := url.Values{"tenant": {"acme"}, "id": {"42"}, "tab": {"info"}, "ref": {"mail"}}
+	u, err := b.URL(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "http://acme.domain.com/users/42?ref=mail&tab=info" {
+		t.Errorf("expected %q, got %q", "http://acme.domain.com/users/42?ref=mail&tab=info", s)
+	}
+	if len(values["id"]) != 1 {
+		t.Errorf("expected values to be left unchanged, got %v", values)
+	}
+	if _, err := b.URL(url.Values{"tenant": {"acme"}}); err == nil {
+		t.Errorf("expected error for missing values")
+	}
+}
+
+func TestBuildWith(t *testing.T) {
+	b := MustNewGorillaPath("/users/{id}", false)
+	tests := []struct {
+		opts BuildOptions
+		url  string
+	}{
+		{BuildOptions{}, "/users/42"},
+		{BuildOptions{AppendQuery: true}, "/users/42?a=1&a=2&b=3"},
+		{BuildOptions{Fragment: "section 2"}, "/users/42#section%202"},
+		{BuildOptions{AppendQuery: true, Fragment: "top"}, "/users/42?a=1&a=2&b=3#top"},
+	}
+	for _, test := range tests {
+		u := &url.URL{}
+		values := url.Values{"id": {"42"}, "a": {"1", "2"}, "b": {"3"}}
+		if err := BuildWith(b, u, values, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		if s := u.String(); s != test.url {
+			t.Errorf("%+v: expected %q, got %q", test.opts, test.url, s)
+		}
+	}
+}
+
+func TestFragment(t *testing.T) {
+	b := NewURLBuilder(MustNewGorillaPath("/docs/{page}", false), Fragment("install"))
+	u, err := b.URL(url.Values{"page": {"intro"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "/docs/intro#install" {
+		t.Errorf("expected %q, got %q", "/docs/intro#install", s)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	path := MustNewGorillaPath("/users/{id}", false)
+	host := MustNewGorillaHost("{sub}.domain.com")
+	tests := []struct {
+		base    string
+		builder Builder
+		values  url.Values
+		expect  string
+	}{
+		{"https://domain.com", path, url.Values{"id": {"42"}}, "https://domain.com/users/42"},
+		{"https://cdn.domain.com/app/", path, url.Values{"id": {"42"}},
+			"https://cdn.domain.com/app/users/42"},
+		{"https://domain.com", NewURLBuilder(host, path),
+			url.Values{"sub": {"api"}, "id": {"42"}}, "https://api.domain.com/users/42"},
+	}
+	for _, v := range tests {
+		b, err := NewBaseURL(v.base, v.builder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := b.URL(v.values)
+		if err != nil {
+			t.Errorf("%s: %v", v.base, err)
+		} else if s := u.String(); s != v.expect {
+			t.Errorf("%s: expected %s, got %s", v.base, v.expect, s)
+		}
+	}
+	if _, err := NewBaseURL("/relative", path); err == nil {
+		t.Errorf("expected an error for a relative base URL")
+	}
+	b, _ := NewBaseURL("https://domain.com", path)
+	if _, err := b.URL(url.Values{}); err == nil {
+		t.Errorf("expected an error for a missing variable")
+	}
+}
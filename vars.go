@@ -0,0 +1,136 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// VarType lists the types supported by typed variables.
+type VarType interface {
+	~string | ~bool | ~int | ~int64 | ~uint | ~uint64 | ~float64
+}
+
+// Var is a typed variable descriptor. It is a variable name that knows the
+// type of its value, so that extracted values can be read and URLs can be
+// built without converting from and to strings by hand:
+//
+//	var userID = reverse.Var[int]("id")
+//
+//	path := reverse.MustNewGorillaPath("/users/"+userID.Placeholder(), false)
+//	// ...
+//	id, err := reverse.Get(result, userID)
+//	// ...
+//	err = path.Build(u, reverse.NewValues(userID.Set(42)))
+type Var[T VarType] string
+
+// Name returns the variable name.
+func (v Var[T]) Name() string {
+	return string(v)
+}
+
+// Placeholder returns a Gorilla pattern placeholder for the variable, using
+// a regexp that matches the variable type; for example `{id:-?[0-9]+}` for
+// a Var[int] named "id".
+func (v Var[T]) Placeholder() string {
+	var zero T
+	switch any(zero).(type) {
+	case int, int64:
+		return "{" + string(v) + ":-?[0-9]+}"
+	case uint, uint64:
+		return "{" + string(v) + ":[0-9]+}"
+	case bool:
+		return "{" + string(v) + ":(?:true|false)}"
+	}
+	return "{" + string(v) + "}"
+}
+
+// Set returns an argument that sets the variable to the given value.
+func (v Var[T]) Set(value T) Arg {
+	return Arg{Name: string(v), Value: formatVar(value)}
+}
+
+// Parse converts a string to the variable type.
+func (v Var[T]) Parse(s string) (T, error) {
+	var value T
+	var err error
+	switch p := any(&value).(type) {
+	case *string:
+		*p = s
+	case *bool:
+		*p, err = strconv.ParseBool(s)
+	case *int:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 0)
+		*p = int(i)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+	case *uint:
+		var i uint64
+		i, err = strconv.ParseUint(s, 10, 0)
+		*p = uint(i)
+	case *uint64:
+		*p, err = strconv.ParseUint(s, 10, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+	default:
+		// Named types based on the supported ones.
+		_, err = fmt.Sscan(s, &value)
+	}
+	if err != nil {
+		return value, fmt.Errorf("Invalid value %q for variable %q: %v",
+			s, string(v), err)
+	}
+	return value, nil
+}
+
+// Arg is a named variable value, as returned by Var.Set.
+type Arg struct {
+	Name  string
+	Value string
+}
+
+// NewValues returns url.Values containing the given arguments, in order.
+func NewValues(args ...Arg) url.Values {
+	values := url.Values{}
+	for _, arg := range args {
+		values.Add(arg.Name, arg.Value)
+	}
+	return values
+}
+
+// Get returns the first value extracted for the given variable, converted
+// to the variable type.
+func Get[T VarType](result *Result, v Var[T]) (T, error) {
+	values := result.Values[string(v)]
+	if len(values) == 0 {
+		var zero T
+		return zero, fmt.Errorf("Missing variable %q", string(v))
+	}
+	return v.Parse(values[0])
+}
+
+// formatVar converts a typed value to a string.
+func formatVar[T VarType](value T) string {
+	switch v := any(value).(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,44 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestVar(t *testing.T) {
+	userID := Var[int]("id")
+	slug := Var[string]("slug")
+	m := MustNewGorillaPath("/users/"+userID.Placeholder()+"/"+slug.Placeholder(), false)
+	r, err := http.NewRequest("GET", "http://domain.com/users/-42/foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", m, r, true)
+	result := Result{}
+	m.Extract(&result, r)
+	id, err := Get(&result, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != -42 {
+		t.Errorf("expected %d, got %d", -42, id)
+	}
+	if _, err := Get[int](&result, "slug"); err == nil {
+		t.Errorf("expected error parsing %q as int", "foo")
+	}
+	if _, err := Get[int](&result, "missing"); err == nil {
+		t.Errorf("expected error for missing variable")
+	}
+	u := url.URL{}
+	if err := m.Build(&u, NewValues(userID.Set(7), slug.Set("bar"))); err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/users/7/bar" {
+		t.Errorf("expected %q, got %q", "/users/7/bar", u.Path)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// varType is a variable type usable in Gorilla patterns.
+type varType struct {
+	pattern string
+	convert func(string) (interface{}, error)
+	openAPI string // OpenAPI schema type, if known
+}
+
+// timeTypePrefix prefixes the time layouts used as variable types, like
+// `{date:time:2006-01-02}`.
+const timeTypePrefix = "time:"
+
+var (
+	varTypesMu sync.RWMutex
+	varTypes   = map[string]varType{
+		"int": {`-?[0-9]+`, func(s string) (interface{}, error) {
+			return strconv.ParseInt(s, 10, 64)
+		}, "integer"},
+		"uint": {`[0-9]+`, func(s string) (interface{}, error) {
+			return strconv.ParseUint(s, 10, 64)
+		}, "integer"},
+		"bool": {`(?:true|false)`, func(s string) (interface{}, error) {
+			return strconv.ParseBool(s)
+		}, "boolean"},
+		"alpha": {`[a-zA-Z]+`, nil, "string"},
+		"alnum": {`[a-zA-Z0-9]+`, nil, "string"},
+		"uuid": {`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-` +
+			`[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+			func(s string) (interface{}, error) {
+				return strings.ToLower(s), nil
+			}, "string"},
+	}
+)
+
+// RegisterVarType registers a variable type for Gorilla patterns, so that
+// `{name:typ}` uses the given regexp pattern. The convert function, if not
+// nil, converts extracted values for TypedValues. It replaces any type
+// previously registered with the name.
+//
+// The built-in types are:
+//
+//   - "int": converted to int64;
+//   - "uint": converted to uint64;
+//   - "bool": converted to bool;
+//   - "alpha" and "alnum": ASCII letters, and letters or digits;
+//   - "uuid": converted to a lower-case string.
+//
+// Numeric time layouts prefixed by "time:", like `{date:time:2006-01-02}`,
+// are types too: they match any digits in place of the layout ones and are
+// converted to time.Time.
+func RegisterVarType(name, pattern string, convert func(string) (interface{}, error)) {
+	varTypesMu.Lock()
+	defer varTypesMu.Unlock()
+	varTypes[name] = varType{pattern: pattern, convert: convert}
+}
+
+// lookupVarType returns the variable type for a Gorilla variable pattern.
+func lookupVarType(name string) (varType, bool) {
+	varTypesMu.RLock()
+	t, ok := varTypes[name]
+	varTypesMu.RUnlock()
+	layout := strings.TrimPrefix(name, timeTypePrefix)
+	if ok || layout == name {
+		return t, ok
+	}
+	var b strings.Builder
+	for _, r := range layout {
+		if r >= '0' && r <= '9' {
+			b.WriteString("[0-9]")
+		} else {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return varType{b.String(), func(s string) (interface{}, error) {
+		return time.Parse(layout, s)
+	}, "string"}, true
+}
+
+// TypedValues converts the given values, as extracted by the matcher, to
+// the types of the variables declared in its Gorilla patterns; for example,
+// `{id:int}` values are converted to int64. Values of other variables are
+// returned as strings. Only the first value of each variable is used, and
+// positional values are ignored.
+func TypedValues(m Matcher, values url.Values) (map[string]interface{}, error) {
+	types := map[string]string{}
+	walkMatchers(m, func(m Matcher) {
+		segments, _ := ParseGorillaTemplate(gorillaPatternOf(m))
+		for _, seg := range segments {
+			if seg.Kind == VariableSegment {
+				types[seg.Name] = seg.Pattern
+			}
+		}
+	})
+	typed := map[string]interface{}{}
+	for name, v := range values {
+		if name == "" || len(v) == 0 {
+			continue
+		}
+		t, ok := lookupVarType(types[name])
+		if !ok || t.convert == nil {
+			typed[name] = v[0]
+			continue
+		}
+		value, err := t.convert(v[0])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %q for variable %q: %v",
+				v[0], name, err)
+		}
+		typed[name] = value
+	}
+	return typed, nil
+}
+
+// gorillaPatternOf returns the pattern of a Gorilla matcher, or an empty
+// string.
+func gorillaPatternOf(m Matcher) string {
+	switch v := m.(type) {
+	case *GorillaHost:
+		return v.pattern
+	case *HostPort:
+		return v.pattern
+	case *GorillaPath:
+		return v.pattern
+	case *GorillaPathPrefix:
+		return v.pattern
+	case *GorillaQuery:
+		return v.pattern
+	}
+	return ""
+}
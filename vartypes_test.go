@@ -0,0 +1,70 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVarTypes(t *testing.T) {
+	RegisterVarType("upper", `[A-Z]+`, func(s string) (interface{}, error) {
+		return strings.ToLower(s), nil
+	})
+	path := MustNewGorillaPath(
+		"/{id:int}/{slug:alpha}/{uuid:uuid}/{date:time:2006-01-02}/{code:upper}/{other}",
+		false)
+	route := NewAll([]Matcher{NewMethod([]string{"GET"}), path})
+	tests := []struct {
+		path   string
+		expect bool
+	}{
+		{"/-42/abc/A0B1C2D3-0000-1111-2222-333344445555/2012-01-02/XY/z", true},
+		{"/4a/abc/a0b1c2d3-0000-1111-2222-333344445555/2012-01-02/XY/z", false},
+		{"/42/ab1/a0b1c2d3-0000-1111-2222-333344445555/2012-01-02/XY/z", false},
+		{"/42/abc/a0b1c2d3/2012-01-02/XY/z", false},
+		{"/42/abc/a0b1c2d3-0000-1111-2222-333344445555/2012-1-2/XY/z", false},
+	}
+	for _, v := range tests {
+		r, err := http.NewRequest("GET", "http://domain.com"+v.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, "GorillaPath", path, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		extractMatcher(route, &result, r)
+		typed, err := TypedValues(route, result.Values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]interface{}{
+			"id":    int64(-42),
+			"slug":  "abc",
+			"uuid":  "a0b1c2d3-0000-1111-2222-333344445555",
+			"date":  time.Date(2012, 1, 2, 0, 0, 0, 0, time.UTC),
+			"code":  "xy",
+			"other": "z",
+		}
+		if fmt.Sprint(typed) != fmt.Sprint(expect) {
+			t.Errorf("expected %v, got %v", expect, typed)
+		}
+	}
+	if _, err := TypedValues(path, map[string][]string{"date": {"2012-13-45"}}); err == nil {
+		t.Errorf("expected error for invalid date")
+	}
+	// Without the prefix, a pattern with "2006" is a regexp.
+	year := MustNewGorillaPath("/{year:2006|2007}", false)
+	r, err := http.NewRequest("GET", "http://domain.com/2007", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testMatcher(t, "GorillaPath", year, r, true)
+}
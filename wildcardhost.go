@@ -0,0 +1,81 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SubdomainKey is the variable name used by WildcardHost for the subdomain.
+const SubdomainKey = "subdomain"
+
+// NewWildcardHost returns a matcher for hosts under a domain, like
+// "*.example.com". A single asterisk matches one label, like
+// "acme.example.com"; a double one matches one or more labels, like
+// "eu.acme.example.com". The domain itself doesn't match.
+func NewWildcardHost(pattern string, opts ...Option) (*WildcardHost, error) {
+	var sub, domain string
+	switch {
+	case strings.HasPrefix(pattern, "**."):
+		sub, domain = `[^.]+(?:\.[^.]+)*`, pattern[3:]
+	case strings.HasPrefix(pattern, "*."):
+		sub, domain = `[^.]+`, pattern[2:]
+	default:
+		return nil, fmt.Errorf("Wildcard host %q must start with %q or %q",
+			pattern, "*.", "**.")
+	}
+	if domain == "" || strings.Contains(domain, "*") {
+		return nil, fmt.Errorf("Invalid wildcard host %q", pattern)
+	}
+	o := newOptions(opts)
+	r, err := o.compile("^(?P<" + SubdomainKey + ">" + sub +
+		`)\.` + regexp.QuoteMeta(domain) + "$")
+	if err != nil {
+		return nil, err
+	}
+	return &WildcardHost{RegexpHost: RegexpHost{Regexp: *r, opts: o}, pattern: pattern}, nil
+}
+
+// MustNewWildcardHost is like NewWildcardHost but panics if the pattern is
+// invalid.
+func MustNewWildcardHost(pattern string, opts ...Option) *WildcardHost {
+	m, err := NewWildcardHost(pattern, opts...)
+	if err != nil {
+		panic(`reverse: NewWildcardHost(` + strconv.Quote(pattern) + `): ` +
+			err.Error())
+	}
+	return m
+}
+
+// WildcardHost matches hosts under a domain. The subdomain is extracted as
+// the "subdomain" variable, and is required to build the host:
+//
+//	m := reverse.MustNewWildcardHost("*.example.com")
+//	// m matches "acme.example.com", extracting subdomain=acme.
+type WildcardHost struct {
+	RegexpHost
+	pattern string
+}
+
+func (m *WildcardHost) String() string {
+	return fmt.Sprintf("WildcardHost(%q)", m.pattern)
+}
+
+// Extract returns the subdomain extracted from the URL host.
+func (m *WildcardHost) Extract(result *Result, r *http.Request) {
+	m.RegexpHost.Extract(result, r)
+	if result.HostPattern == "" {
+		result.HostPattern = m.pattern
+	}
+}
+
+// Pattern returns the wildcard pattern, like "*.example.com".
+func (m *WildcardHost) Pattern() string {
+	return m.pattern
+}
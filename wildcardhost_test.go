@@ -0,0 +1,61 @@
+// Copyright 2012 The Gorilla Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestWildcardHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		expect  bool
+		sub     string
+	}{
+		{"*.example.com", "acme.example.com", true, "acme"},
+		{"*.example.com", "acme.example.com:8080", true, "acme"},
+		{"*.example.com", "eu.acme.example.com", false, ""},
+		{"*.example.com", "example.com", false, ""},
+		{"*.example.com", "acme.exampleXcom", false, ""},
+		{"**.example.com", "eu.acme.example.com", true, "eu.acme"},
+		{"**.example.com", "acme.example.com", true, "acme"},
+		{"**.example.com", "example.com", false, ""},
+	}
+	for _, v := range tests {
+		m := MustNewWildcardHost(v.pattern)
+		r, err := http.NewRequest("GET", "http://"+v.host+"/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testMatcher(t, v.pattern, m, r, v.expect)
+		if !v.expect {
+			continue
+		}
+		result := Result{}
+		m.Extract(&result, r)
+		if s := result.Values.Get(SubdomainKey); s != v.sub {
+			t.Errorf("%s: expected %q, got %q", v.pattern, v.sub, s)
+		}
+	}
+	m := MustNewWildcardHost("*.example.com")
+	u := &url.URL{}
+	if err := m.Build(u, url.Values{SubdomainKey: {"acme"}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := u.String(); s != "http://acme.example.com" {
+		t.Errorf("expected %q, got %q", "http://acme.example.com", s)
+	}
+	if err := m.Build(&url.URL{}, url.Values{SubdomainKey: {"a.b"}}); err == nil {
+		t.Errorf("expected error for a multi-level subdomain")
+	}
+	for _, pattern := range []string{"example.com", "*.", "a.*.example.com", "*.*.com"} {
+		if _, err := NewWildcardHost(pattern); err == nil {
+			t.Errorf("%s: expected an error", pattern)
+		}
+	}
+}